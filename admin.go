@@ -0,0 +1,279 @@
+package arpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a-novel-kit/quicklog"
+)
+
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// Rank of each log level. Messages below the minimum rank of a LevelLogger are dropped.
+var levelRanks = map[quicklog.Level]int{
+	quicklog.LevelInfo:    0,
+	quicklog.LevelWarning: 1,
+	quicklog.LevelError:   2,
+	quicklog.LevelFatal:   3,
+}
+
+// LevelLogger drops messages below a minimum level, which can be changed at runtime.
+type LevelLogger struct {
+	quicklog.Logger
+
+	level atomic.Value
+}
+
+// Level returns the current minimum level.
+func (logger *LevelLogger) Level() quicklog.Level {
+	return logger.level.Load().(quicklog.Level) //nolint:forcetypeassert
+}
+
+// SetLevel changes the minimum level.
+func (logger *LevelLogger) SetLevel(level quicklog.Level) error {
+	if _, ok := levelRanks[level]; !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidLevel, level)
+	}
+
+	logger.level.Store(level)
+
+	return nil
+}
+
+func (logger *LevelLogger) Log(level quicklog.Level, message quicklog.Message) {
+	// Unknown levels are always logged, rather than silently lost.
+	if rank, ok := levelRanks[level]; ok && rank < levelRanks[logger.Level()] {
+		return
+	}
+
+	logger.Logger.Log(level, message)
+}
+
+// NewLevelLogger wraps a logger, so it only logs messages of the given level or above.
+func NewLevelLogger(logger quicklog.Logger, level quicklog.Level) (*LevelLogger, error) {
+	levelLogger := &LevelLogger{Logger: logger}
+	if err := levelLogger.SetLevel(level); err != nil {
+		return nil, err
+	}
+
+	return levelLogger, nil
+}
+
+// AdminConfig configures the admin HTTP endpoint.
+//
+// The admin endpoint exposes profiling data and runtime settings. Only serve it on a port that is not reachable
+// from the outside, or behind an authenticating proxy.
+type AdminConfig struct {
+	// Port of the side server. Required when serving on a side port, unless Listener is set.
+	Port int
+	// Host binds the side server to a specific interface. Empty means all interfaces.
+	Host string
+	// Listener is a custom listener for the side server. It takes precedence over Port and Host.
+	Listener net.Listener
+
+	// Prefix of every admin route. Defaults to "/admin" when the endpoint is mounted on a Gateway, and to no prefix
+	// on a side port. Paths outside the prefix are not served by the admin endpoint.
+	Prefix string
+
+	// Logger is optional. When set, its level can be read and changed at runtime.
+	Logger *LevelLogger
+	// Health is optional. When set, the status of its dependencies is exposed.
+	Health *DepsCheck
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeAdminError(w http.ResponseWriter, code int, err error) {
+	writeAdminJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func adminRuntime(w http.ResponseWriter, _ *http.Request) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"goVersion":  runtime.Version(),
+		"goroutines": runtime.NumGoroutine(),
+		"numCPU":     runtime.NumCPU(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
+		"memory": map[string]interface{}{
+			"heapAlloc": memStats.HeapAlloc,
+			"heapInuse": memStats.HeapInuse,
+			"sys":       memStats.Sys,
+			"numGC":     memStats.NumGC,
+		},
+	})
+}
+
+func adminBuild(w http.ResponseWriter, _ *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, errors.New("build info not available"))
+		return
+	}
+
+	settings := make(map[string]string, len(info.Settings))
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	deps := make([]map[string]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps = append(deps, map[string]string{"path": dep.Path, "version": dep.Version})
+	}
+
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"goVersion": info.GoVersion,
+		"path":      info.Path,
+		"main":      map[string]string{"path": info.Main.Path, "version": info.Main.Version, "sum": info.Main.Sum},
+		"settings":  settings,
+		"deps":      deps,
+	})
+}
+
+func adminLogLevel(logger *LevelLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Level quicklog.Level `json:"level"`
+			}
+
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+				writeAdminError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			if err := logger.SetLevel(quicklog.Level(strings.ToUpper(string(body.Level)))); err != nil {
+				writeAdminError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+
+			return
+		}
+
+		writeAdminJSON(w, http.StatusOK, map[string]quicklog.Level{"level": logger.Level()})
+	}
+}
+
+func adminHealth(depsCheck *DepsCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, depsCheck.Snapshot())
+	}
+}
+
+// NewAdminHandler creates the handler of the admin endpoint. Routes are mounted under the configured prefix:
+//
+//	/debug/pprof/  profiles of the net/http/pprof package
+//	/runtime       goroutine count and memory statistics
+//	/build         build information of the binary
+//	/log/level     current log level, changed with a PUT request: {"level": "WARNING"}
+//	/health        status of the health dependencies
+//
+// Use it to serve the admin endpoint manually. ServerConfig.Admin and GatewayConfig.Admin serve it alongside the
+// GRPC server.
+func NewAdminHandler(config *AdminConfig) http.Handler {
+	prefix := strings.TrimSuffix(config.Prefix, "/")
+	mux := http.NewServeMux()
+
+	// The index serves named profiles, such as goroutine or heap, and relies on the default path.
+	mux.Handle(prefix+"/debug/pprof/", http.StripPrefix(prefix, http.HandlerFunc(pprof.Index)))
+	mux.HandleFunc(prefix+"/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc(prefix+"/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc(prefix+"/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc(prefix+"/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc(prefix+"/runtime", adminRuntime)
+	mux.HandleFunc(prefix+"/build", adminBuild)
+
+	if config.Logger != nil {
+		mux.HandleFunc(prefix+"/log/level", adminLogLevel(config.Logger))
+	}
+
+	if config.Health != nil {
+		mux.HandleFunc(prefix+"/health", adminHealth(config.Health))
+	}
+
+	return mux
+}
+
+func isAdminPath(prefix, urlPath string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+}
+
+func (config *AdminConfig) listen() (net.Listener, error) {
+	if config.Listener != nil {
+		return config.Listener, nil
+	}
+
+	if config.Port == 0 {
+		return nil, fmt.Errorf("admin: %w", ErrPortRequired)
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return nil, fmt.Errorf("admin: %w: %d", ErrInvalidPort, config.Port)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("admin: listen: %w", err)
+	}
+
+	return listener, nil
+}
+
+// adminListener ties the admin server to the listener of the GRPC server, so both are closed together.
+type adminListener struct {
+	net.Listener
+
+	admin *http.Server
+	once  sync.Once
+}
+
+func (listener *adminListener) Close() error {
+	listener.once.Do(func() {
+		_ = listener.admin.Close()
+	})
+
+	return listener.Listener.Close() //nolint:wrapcheck
+}
+
+// startAdmin serves the admin endpoint on a side port, until the returned listener is closed.
+func startAdmin(config *AdminConfig, listener net.Listener) (net.Listener, error) {
+	sideListener, err := config.listen()
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Handler:           NewAdminHandler(config),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		_ = server.Serve(sideListener)
+	}()
+
+	return &adminListener{Listener: listener, admin: server}, nil
+}
@@ -0,0 +1,188 @@
+package arpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+)
+
+func adminRequest(
+	ctx context.Context, t *testing.T, method, url, body string,
+) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+		require.NoError(t, json.Unmarshal(data, &out), string(data))
+	}
+
+	return res.StatusCode, out
+}
+
+func newAdminConfig(t *testing.T) *arpc.AdminConfig {
+	t.Helper()
+
+	logger, err := arpc.NewLevelLogger(quicklogmocks.NewMockLogger(t), quicklog.LevelInfo)
+	require.NoError(t, err)
+
+	return &arpc.AdminConfig{
+		Logger: logger,
+		Health: &arpc.DepsCheck{
+			Dependencies: arpc.DepCheckCallbacks{
+				"database": func() error { return errors.New("uwups") },
+			},
+			Services: arpc.DepCheckServices{
+				"MyService": {"database"},
+			},
+		},
+	}
+}
+
+func checkAdminEndpoints(ctx context.Context, t *testing.T, baseURL string) {
+	t.Helper()
+
+	code, runtimeInfo := adminRequest(ctx, t, http.MethodGet, baseURL+"/runtime", "")
+	require.Equal(t, http.StatusOK, code)
+	require.NotZero(t, runtimeInfo["goroutines"])
+
+	code, _ = adminRequest(ctx, t, http.MethodGet, baseURL+"/build", "")
+	require.Equal(t, http.StatusOK, code)
+
+	code, health := adminRequest(ctx, t, http.MethodGet, baseURL+"/health", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "NOT_SERVING", health["status"])
+	require.Equal(t, map[string]interface{}{"MyService": "NOT_SERVING"}, health["services"])
+
+	code, _ = adminRequest(ctx, t, http.MethodGet, baseURL+"/debug/pprof/goroutine?debug=1", "")
+	require.Equal(t, http.StatusOK, code)
+
+	code, level := adminRequest(ctx, t, http.MethodGet, baseURL+"/log/level", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "INFO", level["level"])
+
+	code, level = adminRequest(ctx, t, http.MethodPut, baseURL+"/log/level", `{"level":"error"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ERROR", level["level"])
+
+	code, _ = adminRequest(ctx, t, http.MethodPut, baseURL+"/log/level", `{"level":"verbose"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestAdminSidePort(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	adminListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	config := newAdminConfig(t)
+	config.Listener = adminListener
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{
+		Ephemeral: true,
+		Host:      "127.0.0.1",
+		Admin:     config,
+	})
+	require.NoError(t, err)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	baseURL := "http://" + adminListener.Addr().String()
+
+	checkAdminEndpoints(ctx, t, baseURL)
+	require.Equal(t, quicklog.LevelError, config.Logger.Level())
+
+	arpc.CloseServer(listener, server)
+
+	_, err = net.Dial("tcp", adminListener.Addr().String())
+	require.Error(t, err)
+}
+
+func TestAdminSidePortRequired(t *testing.T) {
+	_, _, err := arpc.StartServerWithConfig(&arpc.ServerConfig{
+		Ephemeral: true,
+		Host:      "127.0.0.1",
+		Admin:     new(arpc.AdminConfig),
+	})
+	require.ErrorIs(t, err, arpc.ErrPortRequired)
+}
+
+func TestAdminGateway(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
+	require.NoError(t, err)
+
+	gateway, err := arpc.NewGateway(server, &arpc.GatewayConfig{Admin: newAdminConfig(t)})
+	require.NoError(t, err)
+
+	go func() {
+		_ = gateway.Serve(listener)
+	}()
+
+	defer func() {
+		require.NoError(t, gateway.Close())
+	}()
+
+	baseURL := "http://" + listener.Addr().String()
+
+	checkAdminEndpoints(ctx, t, baseURL+"/admin")
+
+	// Routes outside the prefix are still handled by the gateway.
+	code, _ := adminRequest(ctx, t, http.MethodGet, baseURL+"/runtime", "")
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestLevelLogger(t *testing.T) {
+	logger := quicklogmocks.NewMockLogger(t)
+
+	levelLogger, err := arpc.NewLevelLogger(logger, quicklog.LevelWarning)
+	require.NoError(t, err)
+
+	logger.On("Log", quicklog.LevelWarning, mock.Anything).Once()
+	logger.On("Log", quicklog.LevelFatal, mock.Anything).Once()
+
+	levelLogger.Log(quicklog.LevelInfo, nil)
+	levelLogger.Log(quicklog.LevelWarning, nil)
+
+	require.NoError(t, levelLogger.SetLevel(quicklog.LevelFatal))
+
+	levelLogger.Log(quicklog.LevelError, nil)
+	levelLogger.Log(quicklog.LevelFatal, nil)
+
+	require.ErrorIs(t, levelLogger.SetLevel("VERBOSE"), arpc.ErrInvalidLevel)
+	require.Equal(t, quicklog.LevelFatal, levelLogger.Level())
+
+	_, err = arpc.NewLevelLogger(logger, "VERBOSE")
+	require.ErrorIs(t, err, arpc.ErrInvalidLevel)
+
+	logger.AssertExpectations(t)
+}
@@ -58,6 +58,8 @@ type connPoolImpl struct {
 
 	closed  bool
 	release bool
+
+	options []grpc.DialOption
 }
 
 // Make sure the pool is properly initialized when used.
@@ -157,6 +159,8 @@ func (pool *connPoolImpl) Open(
 	// TODO: uncomment when the app grows. This is temporarily deactivated because it drives GCP costs up.
 	// options = append(options, grpc.WithDefaultServiceConfig(grpcConfig))
 
+	options = append(options, pool.options...)
+
 	conn, err := grpc.NewClient(fmt.Sprintf("%s:%d", host, port), options...)
 	if err != nil {
 		return nil, fmt.Errorf("open connection: %w", err)
@@ -170,9 +174,11 @@ func (pool *connPoolImpl) Open(
 	return conn, nil
 }
 
-// NewConnPool creates a new connection pool for GRPC services.
-func NewConnPool(release bool) ConnPool {
+// NewConnPool creates a new connection pool for GRPC services. Options are added to every connection opened by
+// the pool, for example to register client interceptors.
+func NewConnPool(release bool, options ...grpc.DialOption) ConnPool {
 	return &connPoolImpl{
 		release: release,
+		options: options,
 	}
 }
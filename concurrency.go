@@ -0,0 +1,323 @@
+package arpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+var ErrOverloaded = errors.New("server overloaded")
+
+// DefaultPriorityHeader is the metadata key read to determine the priority of a call.
+const DefaultPriorityHeader = "x-priority"
+
+// DefaultConcurrencyLimit is the limit used when a ConcurrencyConfig sets neither InitialLimit nor MaxLimit.
+const DefaultConcurrencyLimit = 100
+
+// Priority levels recognized in the priority header. Unknown or missing values are treated as PriorityNormal.
+const (
+	PriorityLow      = "low"
+	PriorityNormal   = "normal"
+	PriorityHigh     = "high"
+	PriorityCritical = "critical"
+)
+
+// Share of the concurrency limit each priority level may use. Low priority calls are shed first, leaving room
+// for more important ones.
+var priorityShares = map[string]float64{
+	PriorityLow:      0.5,
+	PriorityNormal:   0.8,
+	PriorityHigh:     0.9,
+	PriorityCritical: 1,
+}
+
+// adaptiveLimit implements an AIMD (additive increase, multiplicative decrease) limit. The limit grows by 1 every
+// time a full window of calls succeeds under the latency threshold, and shrinks by a constant ratio every time a
+// call is too slow.
+type adaptiveLimit struct {
+	limit    float64
+	min      float64
+	max      float64
+	inFlight int
+
+	mu sync.Mutex
+}
+
+func newAdaptiveLimit(initial, minLimit, maxLimit int) *adaptiveLimit {
+	return &adaptiveLimit{
+		limit: math.Max(float64(minLimit), math.Min(float64(initial), float64(maxLimit))),
+		min:   float64(minLimit),
+		max:   float64(maxLimit),
+	}
+}
+
+func (limit *adaptiveLimit) acquire(share float64) bool {
+	limit.mu.Lock()
+	defer limit.mu.Unlock()
+
+	// Always let at least one call through, so the limit can recover.
+	if limit.inFlight > 0 && float64(limit.inFlight) >= math.Max(1, math.Floor(limit.limit*share)) {
+		return false
+	}
+
+	limit.inFlight++
+
+	return true
+}
+
+// Returns true if the limit was decreased.
+func (limit *adaptiveLimit) release(overloaded bool, backoff float64) bool {
+	limit.mu.Lock()
+	defer limit.mu.Unlock()
+
+	limit.inFlight--
+
+	if overloaded {
+		previous := math.Floor(limit.limit)
+		limit.limit = math.Max(limit.min, limit.limit*backoff)
+
+		return math.Floor(limit.limit) < previous
+	}
+
+	limit.limit = math.Min(limit.max, limit.limit+1/limit.limit)
+
+	return false
+}
+
+// Free a slot without adjusting the limit, for calls that were shed before reaching the handler.
+func (limit *adaptiveLimit) cancel() {
+	limit.mu.Lock()
+	defer limit.mu.Unlock()
+
+	limit.inFlight--
+}
+
+func (limit *adaptiveLimit) values() (int, int) {
+	limit.mu.Lock()
+	defer limit.mu.Unlock()
+
+	return int(limit.limit), limit.inFlight
+}
+
+// ConcurrencyConfig configures a ConcurrencyLimiter.
+type ConcurrencyConfig struct {
+	// InitialLimit, MinLimit and MaxLimit bound the number of calls the server handles at once. MinLimit defaults
+	// to 1, and MaxLimit to InitialLimit, so the limit can only decrease from there. If neither InitialLimit nor
+	// MaxLimit is set, both default to DefaultConcurrencyLimit.
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+
+	// MethodLimits caps the number of concurrent calls for the methods matching a pattern, following the syntax
+	// of path.Match. Those caps adapt the same way the global limit does, and are never exceeded.
+	MethodLimits map[string]int
+
+	// LatencyThreshold is the latency above which a call is considered a sign of overload.
+	LatencyThreshold time.Duration
+	// Backoff is the ratio applied to the limit when overload is detected. Defaults to 0.9.
+	Backoff float64
+
+	// PriorityHeader is the metadata key used to read the priority of a call. Defaults to DefaultPriorityHeader.
+	PriorityHeader string
+
+	// Logger is optional. When set, it receives a report every time the limit decreases.
+	Logger quicklog.Logger
+}
+
+// ConcurrencyLimiter sheds calls that exceed an adaptive concurrency limit, rather than letting them queue.
+type ConcurrencyLimiter struct {
+	config  *ConcurrencyConfig
+	global  *adaptiveLimit
+	methods map[string]*adaptiveLimit
+}
+
+// Limit returns the current global limit.
+func (limiter *ConcurrencyLimiter) Limit() int {
+	limit, _ := limiter.global.values()
+	return limit
+}
+
+// InFlight returns the number of calls currently handled.
+func (limiter *ConcurrencyLimiter) InFlight() int {
+	_, inFlight := limiter.global.values()
+	return inFlight
+}
+
+// HealthCheck returns ErrOverloaded while the server has reached its concurrency limit. It can be used as a
+// dependency of the health server.
+//
+//	arpc.DepCheckCallbacks{"concurrency": limiter.HealthCheck}
+func (limiter *ConcurrencyLimiter) HealthCheck() error {
+	limit, inFlight := limiter.global.values()
+	if inFlight >= limit {
+		return fmt.Errorf("%w: %d calls in flight, limit is %d", ErrOverloaded, inFlight, limit)
+	}
+
+	return nil
+}
+
+func (limiter *ConcurrencyLimiter) priorityShare(ctx context.Context) float64 {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(limiter.config.PriorityHeader)
+	if len(values) == 0 {
+		return priorityShares[PriorityNormal]
+	}
+
+	share, ok := priorityShares[values[0]]
+	if !ok {
+		return priorityShares[PriorityNormal]
+	}
+
+	return share
+}
+
+// Reserve a slot for a call. On success, the returned function must be called once the call completes, with the
+// error it returned.
+func (limiter *ConcurrencyLimiter) acquire(
+	ctx context.Context, fullMethod string, checkLatency bool,
+) (func(err error), error) {
+	share := limiter.priorityShare(ctx)
+
+	var acquired []*adaptiveLimit
+
+	releaseAll := func(overloaded bool) {
+		for _, limit := range acquired {
+			if limit.release(overloaded, limiter.config.Backoff) && limit == limiter.global {
+				limiter.report()
+			}
+		}
+	}
+
+	if !limiter.global.acquire(share) {
+		return nil, status.Errorf(codes.Unavailable, "%s: try again later", ErrOverloaded)
+	}
+
+	acquired = append(acquired, limiter.global)
+
+	for pattern, limit := range limiter.methods {
+		if !matchPattern(pattern, fullMethod) {
+			continue
+		}
+
+		if !limit.acquire(share) {
+			// The call never ran, so it tells nothing about the load of the server.
+			for _, acquiredLimit := range acquired {
+				acquiredLimit.cancel()
+			}
+
+			return nil, status.Errorf(codes.Unavailable, "%s: try again later", ErrOverloaded)
+		}
+
+		acquired = append(acquired, limit)
+	}
+
+	start := time.Now()
+
+	return func(err error) {
+		overloaded := status.Code(err) == codes.DeadlineExceeded ||
+			(checkLatency && limiter.config.LatencyThreshold > 0 && time.Since(start) > limiter.config.LatencyThreshold)
+
+		releaseAll(overloaded)
+	}, nil
+}
+
+func (limiter *ConcurrencyLimiter) report() {
+	if limiter.config.Logger == nil {
+		return
+	}
+
+	limit, inFlight := limiter.global.values()
+	limiter.config.Logger.Log(quicklog.LevelWarning, arpcmessages.NewConcurrencyLimit(limit, inFlight))
+}
+
+// NewConcurrencyLimiter creates a new limiter. Use it with UnaryConcurrencyInterceptor and
+// StreamConcurrencyInterceptor.
+func NewConcurrencyLimiter(config *ConcurrencyConfig) *ConcurrencyLimiter {
+	if config.Backoff <= 0 || config.Backoff >= 1 {
+		config.Backoff = 0.9
+	}
+
+	if config.PriorityHeader == "" {
+		config.PriorityHeader = DefaultPriorityHeader
+	}
+
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+
+	if config.InitialLimit <= 0 && config.MaxLimit <= 0 {
+		config.InitialLimit = DefaultConcurrencyLimit
+	}
+
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = config.InitialLimit
+	}
+
+	if config.MaxLimit < config.MinLimit {
+		config.MaxLimit = config.MinLimit
+	}
+
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = config.MaxLimit
+	}
+
+	limiter := &ConcurrencyLimiter{
+		config:  config,
+		global:  newAdaptiveLimit(config.InitialLimit, config.MinLimit, config.MaxLimit),
+		methods: make(map[string]*adaptiveLimit, len(config.MethodLimits)),
+	}
+
+	for pattern, maxLimit := range config.MethodLimits {
+		limiter.methods[pattern] = newAdaptiveLimit(maxLimit, min(config.MinLimit, maxLimit), maxLimit)
+	}
+
+	return limiter
+}
+
+// UnaryConcurrencyInterceptor sheds unary calls that exceed the limiter capacity, with codes.Unavailable.
+func UnaryConcurrencyInterceptor(limiter *ConcurrencyLimiter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		release, err := limiter.acquire(ctx, info.FullMethod, true)
+		if err != nil {
+			return nil, err
+		}
+
+		// Release the slot even if the handler panics, or it would be lost for good.
+		defer func() { release(err) }()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamConcurrencyInterceptor sheds streams that exceed the limiter capacity, with codes.Unavailable. A stream
+// holds its slot until it terminates.
+func StreamConcurrencyInterceptor(limiter *ConcurrencyLimiter) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) (err error) {
+		// Streams are long-lived by nature, so their duration says nothing about the server load.
+		release, err := limiter.acquire(ss.Context(), info.FullMethod, false)
+		if err != nil {
+			return err
+		}
+
+		defer func() { release(err) }()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,218 @@
+package arpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestConcurrencyInterceptorsShedding(t *testing.T) {
+	limiter := arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{MaxLimit: 2})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			started <- struct{}{}
+			<-release
+
+			return new(testgrpc.Empty), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryConcurrencyInterceptor(limiter)),
+		grpc.ChainStreamInterceptor(arpc.StreamConcurrencyInterceptor(limiter)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	criticalCtx := metadata.AppendToOutgoingContext(ctx, arpc.DefaultPriorityHeader, arpc.PriorityCritical)
+
+	errs := make(chan error, 2)
+	call := func(ctx context.Context) {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		errs <- err
+	}
+
+	// Normal priority calls may only use 80% of the limit.
+	go call(ctx)
+	<-started
+
+	_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Critical calls may use the full capacity.
+	go call(criticalCtx)
+	<-started
+
+	require.Equal(t, 2, limiter.InFlight())
+	require.ErrorIs(t, limiter.HealthCheck(), arpc.ErrOverloaded)
+
+	_, err = stub.Client.EmptyCall(criticalCtx, new(testgrpc.Empty))
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	close(release)
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	require.Equal(t, 0, limiter.InFlight())
+	require.NoError(t, limiter.HealthCheck())
+}
+
+func TestConcurrencyInterceptorsMethodLimits(t *testing.T) {
+	limiter := arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{
+		InitialLimit: 5,
+		MaxLimit:     10,
+		MethodLimits: map[string]int{
+			"/grpc.testing.TestService/EmptyCall": 1,
+		},
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			started <- struct{}{}
+			<-release
+
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryConcurrencyInterceptor(limiter)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errs := make(chan error, 1)
+
+	go func() {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		errs <- err
+	}()
+	<-started
+
+	// Shed calls never ran, so they don't raise the global limit.
+	for range 20 {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	}
+
+	require.Equal(t, 5, limiter.Limit())
+	require.Equal(t, 1, limiter.InFlight())
+
+	// Other methods are only bound by the global limit.
+	_, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+	require.NoError(t, err)
+
+	close(release)
+	require.NoError(t, <-errs)
+}
+
+func TestConcurrencyInterceptorsBackoff(t *testing.T) {
+	logger := quicklogmocks.NewMockLogger(t)
+	logger.On("Log", quicklog.LevelWarning, mock.Anything)
+
+	limiter := arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{
+		MaxLimit:         10,
+		LatencyThreshold: time.Millisecond,
+		Backoff:          0.5,
+		Logger:           logger,
+	})
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			time.Sleep(5 * time.Millisecond)
+			return new(testgrpc.Empty), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryConcurrencyInterceptor(limiter)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for range 2 {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 2, limiter.Limit())
+	logger.AssertNumberOfCalls(t, "Log", 2)
+}
+
+func TestConcurrencyLimiterDefaults(t *testing.T) {
+	require.Equal(t, 100, arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{InitialLimit: 100}).Limit())
+	require.Equal(t, 10, arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{MaxLimit: 10}).Limit())
+	require.Equal(t, arpc.DefaultConcurrencyLimit, arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{}).Limit())
+}
+
+func TestConcurrencyInterceptorsPanic(t *testing.T) {
+	limiter := arpc.NewConcurrencyLimiter(&arpc.ConcurrencyConfig{MaxLimit: 1})
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			panic("uwups")
+		},
+		FullDuplexCallF: func(_ testgrpc.TestService_FullDuplexCallServer) error {
+			panic("uwups")
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			arpc.UnaryRecoveryInterceptor(new(arpc.RecoveryConfig)),
+			arpc.UnaryConcurrencyInterceptor(limiter),
+		),
+		grpc.ChainStreamInterceptor(
+			arpc.StreamRecoveryInterceptor(new(arpc.RecoveryConfig)),
+			arpc.StreamConcurrencyInterceptor(limiter),
+		),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Panicking handlers release their slot, so the next calls are not shed.
+	for range 3 {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		require.Equal(t, codes.Internal, status.Code(err))
+
+		stream, err := stub.Client.FullDuplexCall(ctx)
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Internal, status.Code(err))
+	}
+
+	require.Equal(t, 0, limiter.InFlight())
+}
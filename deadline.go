@@ -0,0 +1,148 @@
+package arpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineRule bounds the duration of the calls to methods matching any of the patterns. Patterns follow the syntax
+// of path.Match.
+type DeadlineRule struct {
+	Methods []string
+	// Default is the deadline applied to calls sent without one. When zero, Max is used instead.
+	Default time.Duration
+	// Max is the longest deadline a call may have. Longer deadlines set by clients are shortened. When zero, client
+	// deadlines are kept as is.
+	Max time.Duration
+}
+
+// DeadlineConfig configures the deadline interceptors. Only the first rule matching a method is applied.
+type DeadlineConfig struct {
+	Rules []DeadlineRule
+}
+
+// Returns the timeout to impose on a call, or 0 if the deadline of the call is kept.
+func (config *DeadlineConfig) timeout(ctx context.Context, fullMethod string) time.Duration {
+	for _, rule := range config.Rules {
+		if !matchAnyPattern(rule.Methods, fullMethod) {
+			continue
+		}
+
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			if rule.Default > 0 {
+				return rule.Default
+			}
+
+			return rule.Max
+		}
+
+		if rule.Max > 0 && time.Until(deadline) > rule.Max {
+			return rule.Max
+		}
+
+		return 0
+	}
+
+	return 0
+}
+
+type serverDeadlineKey struct{}
+
+// serverDeadline remembers the deadline imposed by the server, so it can tell whether it cut a call.
+type serverDeadline struct {
+	timeout time.Duration
+	// Context of the call before the server deadline was applied.
+	parent context.Context
+}
+
+// ServerDeadlineExceeded reports whether the deadline imposed by the deadline interceptors expired, rather than the
+// one set by the client. It returns the imposed timeout.
+func ServerDeadlineExceeded(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Value(serverDeadlineKey{}).(*serverDeadline)
+	if !ok {
+		return 0, false
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) || deadline.parent.Err() != nil {
+		return 0, false
+	}
+
+	return deadline.timeout, true
+}
+
+// Applies the server deadline to a call. The returned function must be called once the call completes, with the
+// error it returned.
+func (config *DeadlineConfig) apply(
+	ctx context.Context, fullMethod string,
+) (context.Context, func(err error) error) {
+	timeout := config.timeout(ctx, fullMethod)
+	if timeout <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+
+	deadlineCtx, cancel := context.WithTimeout(
+		context.WithValue(ctx, serverDeadlineKey{}, &serverDeadline{timeout: timeout, parent: ctx}),
+		timeout,
+	)
+
+	return deadlineCtx, func(err error) error {
+		defer cancel()
+
+		cutTimeout, cut := ServerDeadlineExceeded(deadlineCtx)
+		if !cut {
+			return err
+		}
+
+		annotateReport(ctx, func(annotations *reportAnnotations) {
+			annotations.serverDeadline = cutTimeout
+		})
+
+		// Handlers usually return the context error as is, which would otherwise reach the client as
+		// codes.Unknown.
+		if err != nil && errors.Is(err, context.DeadlineExceeded) {
+			return status.Errorf(codes.DeadlineExceeded, "%s: server deadline of %s exceeded", fullMethod, cutTimeout)
+		}
+
+		return err
+	}
+}
+
+// UnaryDeadlineInterceptor bounds the duration of unary calls, following the rules of the configuration. Calls
+// cut by the server deadline are flagged in the reports of UnaryReportInterceptor and WithReport, when this
+// interceptor runs after them.
+//
+//	grpc.ChainUnaryInterceptor(
+//		arpc.UnaryReportInterceptor(logger),
+//		arpc.UnaryDeadlineInterceptor(&arpc.DeadlineConfig{
+//			Rules: []arpc.DeadlineRule{
+//				{Methods: []string{"/reports.Exporter/*"}, Default: time.Minute, Max: 5 * time.Minute},
+//				{Methods: []string{"*"}, Default: 5 * time.Second, Max: 30 * time.Second},
+//			},
+//		}),
+//	)
+func UnaryDeadlineInterceptor(config *DeadlineConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, done := config.apply(ctx, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+
+		return resp, done(err)
+	}
+}
+
+// StreamDeadlineInterceptor bounds the total duration of streams, following the rules of the configuration.
+func StreamDeadlineInterceptor(config *DeadlineConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := config.apply(ss.Context(), info.FullMethod)
+
+		return done(handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx}))
+	}
+}
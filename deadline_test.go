@@ -0,0 +1,162 @@
+package arpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func matchServerDeadline(service string, deadline time.Duration) interface{} {
+	return mock.MatchedBy(func(message quicklog.Message) bool {
+		request := message.RenderJSON()["grpcRequest"].(map[string]interface{})
+		serverDeadline, ok := request["serverDeadline"]
+
+		if deadline == 0 {
+			return request["service"] == service && !ok
+		}
+
+		return request["service"] == service && serverDeadline == deadline
+	})
+}
+
+func TestDeadlineInterceptors(t *testing.T) {
+	logger := quicklogmocks.NewMockLogger(t)
+
+	// Time left before the deadline, as seen by the handler. Negative when the call has no deadline.
+	remaining := make(chan time.Duration, 1)
+
+	recordDeadline := func(ctx context.Context) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			remaining <- -1
+			return
+		}
+
+		remaining <- time.Until(deadline)
+	}
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(ctx context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			recordDeadline(ctx)
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(ctx context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			recordDeadline(ctx)
+
+			if in.GetFillUsername() {
+				return new(testgrpc.SimpleResponse), nil
+			}
+
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		},
+		FullDuplexCallF: func(stream testgrpc.TestService_FullDuplexCallServer) error {
+			recordDeadline(stream.Context())
+			<-stream.Context().Done()
+
+			return stream.Context().Err()
+		},
+	}
+
+	config := &arpc.DeadlineConfig{
+		Rules: []arpc.DeadlineRule{
+			{Methods: []string{"/grpc.testing.TestService/EmptyCall"}},
+			{Methods: []string{"/grpc.testing.TestService/UnaryCall"}, Default: 50 * time.Millisecond, Max: time.Second},
+			{Methods: []string{"*"}, Max: 50 * time.Millisecond},
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryReportInterceptor(logger), arpc.UnaryDeadlineInterceptor(config)),
+		grpc.ChainStreamInterceptor(arpc.StreamReportInterceptor(logger), arpc.StreamDeadlineInterceptor(config)),
+	}))
+	defer stub.Stop()
+
+	t.Run("NoRule", func(t *testing.T) {
+		logger.On("Log", quicklog.LevelInfo, matchServerDeadline("/grpc.testing.TestService/EmptyCall", 0)).Once()
+
+		_, err := stub.Client.EmptyCall(context.Background(), new(testgrpc.Empty))
+		require.NoError(t, err)
+		require.Negative(t, <-remaining)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		logger.
+			On("Log", quicklog.LevelError, matchServerDeadline(
+				"/grpc.testing.TestService/UnaryCall", 50*time.Millisecond,
+			)).
+			Once()
+
+		_, err := stub.Client.UnaryCall(context.Background(), new(testgrpc.SimpleRequest))
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "server deadline of 50ms exceeded")
+		require.Positive(t, <-remaining)
+	})
+
+	t.Run("Max", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		logger.
+			On("Log", quicklog.LevelInfo, matchServerDeadline("/grpc.testing.TestService/UnaryCall", 0)).
+			Once()
+
+		_, err := stub.Client.UnaryCall(ctx, &testgrpc.SimpleRequest{FillUsername: true})
+		require.NoError(t, err)
+		require.LessOrEqual(t, <-remaining, time.Second)
+	})
+
+	t.Run("ClientDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		done := make(chan struct{})
+
+		// The client deadline is shorter than the maximum, so the server did not cut the call.
+		logger.
+			On("Log", quicklog.LevelError, matchServerDeadline("/grpc.testing.TestService/UnaryCall", 0)).
+			Run(func(_ mock.Arguments) { close(done) }).
+			Once()
+
+		_, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.LessOrEqual(t, <-remaining, 100*time.Millisecond)
+
+		// The client may give up before the server reports the call.
+		<-done
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		done := make(chan struct{})
+
+		logger.
+			On("Log", quicklog.LevelError, matchServerDeadline(
+				"/grpc.testing.TestService/FullDuplexCall", 50*time.Millisecond,
+			)).
+			Run(func(_ mock.Arguments) { close(done) }).
+			Once()
+
+		stream, err := stub.Client.FullDuplexCall(context.Background())
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.Positive(t, <-remaining)
+
+		<-done
+	})
+}
@@ -0,0 +1,126 @@
+package arpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// DebugServicesConfig configures RegisterDebugServices.
+type DebugServicesConfig struct {
+	// SkipInRelease does not register any debug service in release mode.
+	SkipInRelease bool
+	// Principals restricts the debug services to authenticated callers, whose principal, as returned by
+	// PrincipalFromContext, matches one of the patterns. Patterns follow the syntax of path.Match, so "*" allows
+	// every authenticated caller. When empty, debug services are open to everyone.
+	Principals []string
+}
+
+func (config *DebugServicesConfig) authorize(ctx context.Context) error {
+	if len(config.Principals) == 0 {
+		return nil
+	}
+
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "debug services require authentication") //nolint:wrapcheck
+	}
+
+	if !matchAnyPattern(config.Principals, principal) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to use debug services", principal)
+	}
+
+	return nil
+}
+
+// debugRegistrar guards the handlers of the services registered through it.
+type debugRegistrar struct {
+	*grpc.Server
+
+	config     *DebugServicesConfig
+	registered []grpc.ServiceDesc
+}
+
+func (registrar *debugRegistrar) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	guarded := *desc
+	guarded.Methods = make([]grpc.MethodDesc, len(desc.Methods))
+	guarded.Streams = make([]grpc.StreamDesc, len(desc.Streams))
+
+	for i, method := range desc.Methods {
+		handler := method.Handler
+		method.Handler = func(
+			srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+		) (interface{}, error) {
+			// Method handlers run before the interceptors, so the caller is only authenticated once the chain has
+			// run. Authorization is done at the end of the chain instead.
+			guarded := func(
+				ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler,
+			) (interface{}, error) {
+				authorized := func(ctx context.Context, req interface{}) (interface{}, error) {
+					if err := registrar.config.authorize(ctx); err != nil {
+						return nil, err
+					}
+
+					return next(ctx, req)
+				}
+
+				if interceptor == nil {
+					return authorized(ctx, req)
+				}
+
+				return interceptor(ctx, req, info, authorized)
+			}
+
+			return handler(srv, ctx, dec, guarded)
+		}
+
+		guarded.Methods[i] = method
+	}
+
+	for i, stream := range desc.Streams {
+		handler := stream.Handler
+		stream.Handler = func(srv interface{}, ss grpc.ServerStream) error {
+			if err := registrar.config.authorize(ss.Context()); err != nil {
+				return err
+			}
+
+			return handler(srv, ss)
+		}
+
+		guarded.Streams[i] = stream
+	}
+
+	registrar.Server.RegisterService(&guarded, impl)
+	registrar.registered = append(registrar.registered, guarded)
+}
+
+// RegisterDebugServices registers the GRPC reflection (v1 and v1alpha) and channelz services on a server, so it can
+// be inspected with tools such as grpcurl or grpcdebug. It returns the registered services, so they can be listed
+// in the startup message.
+//
+//	services = append(services, arpc.RegisterDebugServices(server, release, &arpc.DebugServicesConfig{
+//		SkipInRelease: true,
+//	})...)
+//	logger.Log(quicklog.LevelInfo, arpcmessages.NewDiscover(services, port))
+//
+// A nil config registers the services without restriction.
+func RegisterDebugServices(server *grpc.Server, release bool, config *DebugServicesConfig) []grpc.ServiceDesc {
+	if config == nil {
+		config = new(DebugServicesConfig)
+	}
+
+	if release && config.SkipInRelease {
+		return nil
+	}
+
+	registrar := &debugRegistrar{Server: server, config: config}
+
+	reflection.Register(registrar)
+	channelzservice.RegisterChannelzServiceToServer(registrar)
+
+	return registrar.registered
+}
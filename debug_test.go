@@ -0,0 +1,187 @@
+package arpc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	channelzgrpc "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func listServicesV1(ctx context.Context, conn grpc.ClientConnInterface) ([]string, error) {
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+
+	return services, nil
+}
+
+func listServicesV1Alpha(ctx context.Context, conn grpc.ClientConnInterface) ([]string, error) {
+	stream, err := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.Send(&reflectionv1alpha.ServerReflectionRequest{
+		MessageRequest: &reflectionv1alpha.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+
+	return services, nil
+}
+
+func TestRegisterDebugServices(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := func(t *testing.T, release bool, config *arpc.DebugServicesConfig, options ...grpc.ServerOption) (
+		*arpcmocks.StubServer, []grpc.ServiceDesc,
+	) {
+		t.Helper()
+
+		var registered []grpc.ServiceDesc
+
+		stub := new(arpcmocks.StubServer)
+		require.NoError(t, stub.Start(append(options, arpcmocks.RegisterServiceServerOption(
+			func(registrar grpc.ServiceRegistrar) {
+				registered = arpc.RegisterDebugServices(registrar.(*grpc.Server), release, config)
+			},
+		))))
+		t.Cleanup(stub.Stop)
+
+		return stub, registered
+	}
+
+	t.Run("Open", func(t *testing.T) {
+		stub, registered := start(t, false, nil)
+
+		var names []string
+		for _, service := range registered {
+			names = append(names, service.ServiceName)
+		}
+
+		require.ElementsMatch(t, []string{
+			"grpc.reflection.v1.ServerReflection",
+			"grpc.reflection.v1alpha.ServerReflection",
+			"grpc.channelz.v1.Channelz",
+		}, names)
+
+		services, err := listServicesV1(ctx, stub.CC)
+		require.NoError(t, err)
+		require.Contains(t, services, "grpc.testing.TestService")
+
+		services, err = listServicesV1Alpha(ctx, stub.CC)
+		require.NoError(t, err)
+		require.Contains(t, services, "grpc.testing.TestService")
+
+		servers, err := channelzgrpc.NewChannelzClient(stub.CC).GetServers(ctx, new(channelzgrpc.GetServersRequest))
+		require.NoError(t, err)
+		require.NotEmpty(t, servers.GetServer())
+	})
+
+	t.Run("SkipInRelease", func(t *testing.T) {
+		stub, registered := start(t, true, &arpc.DebugServicesConfig{SkipInRelease: true})
+
+		require.Empty(t, registered)
+
+		_, err := listServicesV1(ctx, stub.CC)
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		stub, _ := start(t, false, &arpc.DebugServicesConfig{Principals: []string{"*"}})
+
+		_, err := listServicesV1(ctx, stub.CC)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = channelzgrpc.NewChannelzClient(stub.CC).GetServers(ctx, new(channelzgrpc.GetServersRequest))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Principals", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		idTokenConfig := &arpc.IDTokenConfig{
+			Keys:      arpc.StaticKeySource{"key-1": &key.PublicKey},
+			Audiences: []string{"https://my-service.run.app"},
+		}
+
+		stub, _ := start(
+			t, false, &arpc.DebugServicesConfig{Principals: []string{"admin@*"}},
+			grpc.ChainUnaryInterceptor(arpc.UnaryIDTokenInterceptor(idTokenConfig)),
+			grpc.ChainStreamInterceptor(arpc.StreamIDTokenInterceptor(idTokenConfig)),
+		)
+
+		withToken := func(t *testing.T, email string) context.Context {
+			t.Helper()
+
+			token, err := arpcmocks.SignIDToken(key, "key-1", idTokenClaims(map[string]interface{}{"email": email}))
+			require.NoError(t, err)
+
+			return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+
+		services, err := listServicesV1(withToken(t, "admin@project.iam.gserviceaccount.com"), stub.CC)
+		require.NoError(t, err)
+		require.Contains(t, services, "grpc.testing.TestService")
+
+		_, err = listServicesV1(withToken(t, "caller@project.iam.gserviceaccount.com"), stub.CC)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		channelz := channelzgrpc.NewChannelzClient(stub.CC)
+
+		servers, err := channelz.GetServers(
+			withToken(t, "admin@project.iam.gserviceaccount.com"), new(channelzgrpc.GetServersRequest),
+		)
+		require.NoError(t, err)
+		require.NotEmpty(t, servers.GetServer())
+
+		_, err = channelz.GetServers(
+			withToken(t, "caller@project.iam.gserviceaccount.com"), new(channelzgrpc.GetServersRequest),
+		)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -1,145 +1,255 @@
 package arpc
 
 import (
-	"errors"
-	"sync"
-
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
 )
 
+// ErrorHandler converts errors into statuses. Handlers are immutable: every method returns a modified copy, and
+// leaves the receiver untouched, so a shared handler can safely be extended by many services.
 type ErrorHandler interface {
 	Is(target error, code codes.Code) ErrorHandler
 	IsW(target error, code codes.Code, wrap error) ErrorHandler
 	IsWF(target error, code codes.Code, format string, args ...interface{}) ErrorHandler
+	// IsD is similar to Is, but the status carries google.rpc details built from the error.
+	IsD(target error, code codes.Code, details ...ErrorDetail) ErrorHandler
 
 	As(target interface{}, code codes.Code) ErrorHandler
 	AsW(target interface{}, code codes.Code, wrap error) ErrorHandler
 	AsWF(target interface{}, code codes.Code, format string, args ...interface{}) ErrorHandler
+	// AsD is similar to As, but the status carries google.rpc details built from the error.
+	AsD(target interface{}, code codes.Code, details ...ErrorDetail) ErrorHandler
 
 	Test(caseFn func(initialErr error) (err error, ok bool)) ErrorHandler
+	// Add adds cases to the handler, in order.
+	Add(cases ...ErrorCase) ErrorHandler
+	// Include adds the cases of groups to the handler, in order.
+	Include(groups ...*ErrorCaseGroup) ErrorHandler
+	// Named renames the case added last. Cases are otherwise named after their target.
+	//
+	//	handler := arpc.HandleError(codes.Internal).Is(ErrNotFound, codes.NotFound).Named("book not found")
+	Named(name string) ErrorHandler
+
+	// Clone returns a copy of the handler. Handlers are immutable, so the copy is only useful to detach a handler
+	// from the one it was built from, for example before handing it to third party code.
+	Clone() ErrorHandler
+
+	// Passthrough sets how errors that already are statuses, for example when returned by a call to another
+	// service, are handled when they match no case.
+	Passthrough(policy StatusPolicy) ErrorHandler
+	// Sanitize hides the message of the statuses returned by Handle, for the configured codes. A nil config
+	// disables sanitization.
+	Sanitize(config *SanitizeConfig) ErrorHandler
+	// Instrument counts and traces the errors handled. A nil config disables instrumentation.
+	Instrument(config *ErrorInstrumentation) ErrorHandler
 
 	Handle(err error) error
+	// Explain handles an error like Handle does, and returns the cases evaluated to produce the result.
+	Explain(err error) *ErrorTrace
+	// Mappings lists the cases of the handler, followed by the treatment of statuses and other errors. Log them at
+	// startup with arpcmessages.NewErrorMappings, so the whole mapping can be reviewed.
+	Mappings() []arpcmessages.ErrorMapping
+}
+
+// StatusMode is the way an ErrorHandler treats statuses that match no case.
+type StatusMode int
+
+const (
+	// StatusWrap wraps statuses into the default code of the handler, like any other error. This is the default.
+	StatusWrap StatusMode = iota
+	// StatusKeep returns statuses as is.
+	StatusKeep
+	// StatusRemap translates the code of statuses, using StatusPolicy.Remap.
+	StatusRemap
+)
+
+// StatusPolicy configures how an ErrorHandler treats statuses that match no case.
+type StatusPolicy struct {
+	Mode StatusMode
+	// Remap translates the codes of statuses, in StatusRemap mode. Codes missing from the table are translated
+	// to the default code of the handler.
+	//
+	//	// Upstream outages are ours too, but invalid arguments sent upstream are our fault.
+	//	Remap: map[codes.Code]codes.Code{
+	//		codes.Unavailable:     codes.Unavailable,
+	//		codes.InvalidArgument: codes.Internal,
+	//	}
+	Remap map[codes.Code]codes.Code
+	// PropagateDetails keeps the details of statuses, in StatusWrap and StatusRemap modes. Statuses returned
+	// in StatusKeep mode always keep their details.
+	PropagateDetails bool
 }
 
 type errorHandlerImpl struct {
-	cases       []func(err error) (error, bool)
-	defaultCode codes.Code
+	cases        []ErrorCase
+	defaultCode  codes.Code
+	statusPolicy StatusPolicy
+	sanitizer    *SanitizeConfig
+	instrument   *ErrorInstrumentation
+}
 
-	mu sync.RWMutex
+// Returns a modified copy of the handler.
+func (errorHandler *errorHandlerImpl) with(update func(output *errorHandlerImpl)) ErrorHandler {
+	output := errorHandler.clone()
+	update(output)
+
+	return output
 }
 
 func (errorHandler *errorHandlerImpl) Test(caseFn func(initialErr error) (err error, ok bool)) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
-
-	errorHandler.cases = append(errorHandler.cases, caseFn)
-	return errorHandler
+	return errorHandler.Add(NewErrorCase("test", caseFn))
 }
 
-func (errorHandler *errorHandlerImpl) Is(target error, code codes.Code) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
+func (errorHandler *errorHandlerImpl) Add(cases ...ErrorCase) ErrorHandler {
+	return errorHandler.with(func(output *errorHandlerImpl) {
+		output.cases = append(output.cases, cases...)
+	})
+}
 
-	errorHandler.cases = append(errorHandler.cases, func(err error) (error, bool) {
-		if !errors.Is(err, target) {
-			return nil, false
+func (errorHandler *errorHandlerImpl) Named(name string) ErrorHandler {
+	return errorHandler.with(func(output *errorHandlerImpl) {
+		if len(output.cases) > 0 {
+			last := len(output.cases) - 1
+			output.cases[last] = output.cases[last].Named(name)
 		}
-
-		return status.Errorf(code, "%s", err), true
 	})
-	return errorHandler
 }
 
-func (errorHandler *errorHandlerImpl) IsW(target error, code codes.Code, wrap error) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
-
-	errorHandler.cases = append(errorHandler.cases, func(err error) (error, bool) {
-		if !errors.Is(err, target) {
-			return nil, false
+func (errorHandler *errorHandlerImpl) Include(groups ...*ErrorCaseGroup) ErrorHandler {
+	return errorHandler.with(func(output *errorHandlerImpl) {
+		for _, group := range groups {
+			output.cases = append(output.cases, group.cases...)
 		}
-
-		return status.Errorf(code, "%s", errors.Join(wrap, err)), true
 	})
-	return errorHandler
+}
+
+func (errorHandler *errorHandlerImpl) Is(target error, code codes.Code) ErrorHandler {
+	return errorHandler.Add(CaseIs(target, code))
+}
+
+func (errorHandler *errorHandlerImpl) IsW(target error, code codes.Code, wrap error) ErrorHandler {
+	return errorHandler.Add(CaseIsW(target, code, wrap))
 }
 
 func (errorHandler *errorHandlerImpl) IsWF(
 	target error, code codes.Code, format string, args ...interface{},
 ) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
-
-	errorHandler.cases = append(errorHandler.cases, func(err error) (error, bool) {
-		if !errors.Is(err, target) {
-			return nil, false
-		}
+	return errorHandler.Add(CaseIsWF(target, code, format, args...))
+}
 
-		return status.Errorf(code, format, args...), true
-	})
-	return errorHandler
+func (errorHandler *errorHandlerImpl) IsD(target error, code codes.Code, details ...ErrorDetail) ErrorHandler {
+	return errorHandler.Add(CaseIsD(target, code, details...))
 }
 
 func (errorHandler *errorHandlerImpl) As(target interface{}, code codes.Code) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
-
-	errorHandler.cases = append(errorHandler.cases, func(err error) (error, bool) {
-		if !errors.As(err, target) {
-			return nil, false
-		}
-
-		return status.Errorf(code, "%s", err), true
-	})
-	return errorHandler
+	return errorHandler.Add(CaseAs(target, code))
 }
 
 func (errorHandler *errorHandlerImpl) AsW(target interface{}, code codes.Code, wrap error) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
-
-	errorHandler.cases = append(errorHandler.cases, func(err error) (error, bool) {
-		if !errors.As(err, target) {
-			return nil, false
-		}
-
-		return status.Errorf(code, "%s", errors.Join(wrap, err)), true
-	})
-	return errorHandler
+	return errorHandler.Add(CaseAsW(target, code, wrap))
 }
 
 func (errorHandler *errorHandlerImpl) AsWF(
 	target interface{}, code codes.Code, format string, args ...interface{},
 ) ErrorHandler {
-	errorHandler.mu.Lock()
-	defer errorHandler.mu.Unlock()
+	return errorHandler.Add(CaseAsWF(target, code, format, args...))
+}
+
+func (errorHandler *errorHandlerImpl) AsD(target interface{}, code codes.Code, details ...ErrorDetail) ErrorHandler {
+	return errorHandler.Add(CaseAsD(target, code, details...))
+}
+
+func (errorHandler *errorHandlerImpl) Passthrough(policy StatusPolicy) ErrorHandler {
+	return errorHandler.with(func(output *errorHandlerImpl) {
+		output.statusPolicy = policy
+	})
+}
+
+func (errorHandler *errorHandlerImpl) passthrough(err error, st *status.Status) error {
+	policy := errorHandler.statusPolicy
+
+	if policy.Mode == StatusKeep {
+		return st.Err() //nolint:wrapcheck
+	}
+
+	// Proto returns a copy, that can safely be modified.
+	output := st.Proto()
+	if !policy.PropagateDetails {
+		output.Details = nil
+	}
 
-	errorHandler.cases = append(errorHandler.cases, func(err error) (error, bool) {
-		if !errors.As(err, target) {
-			return nil, false
+	switch policy.Mode {
+	case StatusRemap:
+		code, ok := policy.Remap[st.Code()]
+		if !ok {
+			code = errorHandler.defaultCode
 		}
 
-		return status.Errorf(code, format, args...), true
+		output.Code = int32(code) //nolint:gosec
+	default:
+		output.Code = int32(errorHandler.defaultCode) //nolint:gosec
+		output.Message = err.Error()
+	}
+
+	return status.FromProto(output).Err() //nolint:wrapcheck
+}
+
+func (errorHandler *errorHandlerImpl) Sanitize(config *SanitizeConfig) ErrorHandler {
+	return errorHandler.with(func(output *errorHandlerImpl) {
+		output.sanitizer = config
 	})
-	return errorHandler
 }
 
 func (errorHandler *errorHandlerImpl) Handle(err error) error {
-	errorHandler.mu.RLock()
-	defer errorHandler.mu.RUnlock()
+	return errorHandler.explain(err, errorHandler.instrument.tracing()).Result
+}
+
+func (errorHandler *errorHandlerImpl) Explain(err error) *ErrorTrace {
+	return errorHandler.explain(err, true)
+}
+
+// Listing the evaluated cases allocates, so it is only done when the trace is used.
+func (errorHandler *errorHandlerImpl) explain(err error, evaluated bool) *ErrorTrace {
+	trace := &ErrorTrace{Err: err}
+	trace.Result = errorHandler.sanitizer.sanitize(err, errorHandler.handle(err, trace, evaluated))
+
+	errorHandler.instrument.record(trace)
 
-	for _, caseFn := range errorHandler.cases {
-		if res, ok := caseFn(err); ok {
+	return trace
+}
+
+func (errorHandler *errorHandlerImpl) handle(err error, trace *ErrorTrace, evaluated bool) error {
+	for _, errorCase := range errorHandler.cases {
+		if evaluated {
+			trace.Evaluated = append(trace.Evaluated, errorCase.name)
+		}
+
+		if res, ok := errorCase.fn(err); ok {
+			trace.Matched = errorCase.name
 			return res
 		}
 	}
 
+	if st, ok := status.FromError(err); ok && err != nil {
+		trace.Matched = passthroughCaseName
+		return errorHandler.passthrough(err, st)
+	}
+
+	trace.Matched = defaultCaseName
+
 	return status.Errorf(errorHandler.defaultCode, "%s", err)
 }
 
 // HandleError creates a new error handler. You can define different case depending on the error you want to handle.
-// This handler is thread safe, and a single handler can be shared between multiple goroutines.
+// Handlers are immutable, so a single handler can be shared between multiple goroutines, and extended without
+// affecting the other users.
+//
+//	var baseHandler = arpc.HandleError(codes.Internal).Is(ErrNotFound, codes.NotFound)
+//
+//	// baseHandler is not modified.
+//	booksHandler := baseHandler.Is(ErrBookArchived, codes.FailedPrecondition)
 func HandleError(defaultCode codes.Code) ErrorHandler {
 	return &errorHandlerImpl{defaultCode: defaultCode}
 }
@@ -2,11 +2,13 @@ package arpc_test
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -256,3 +258,142 @@ func TestHandleErrorConcurrency(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleErrorStatusPassthrough(t *testing.T) {
+	upstream, err := status.New(codes.NotFound, "book not found").
+		WithDetails(&errdetails.ErrorInfo{Reason: "BOOK_NOT_FOUND"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+
+		policy *arpc.StatusPolicy
+		err    error
+
+		expectCode    codes.Code
+		expectMessage string
+		expectDetails bool
+	}{
+		{
+			name: "Wrap",
+
+			err: upstream.Err(),
+
+			expectCode:    codes.Internal,
+			expectMessage: "rpc error: code = NotFound desc = book not found",
+		},
+		{
+			name: "WrapWithDetails",
+
+			policy: &arpc.StatusPolicy{Mode: arpc.StatusWrap, PropagateDetails: true},
+			err:    upstream.Err(),
+
+			expectCode:    codes.Internal,
+			expectMessage: "rpc error: code = NotFound desc = book not found",
+			expectDetails: true,
+		},
+		{
+			name: "Keep",
+
+			policy: &arpc.StatusPolicy{Mode: arpc.StatusKeep},
+			err:    upstream.Err(),
+
+			expectCode:    codes.NotFound,
+			expectMessage: "book not found",
+			expectDetails: true,
+		},
+		{
+			name: "KeepWrapped",
+
+			policy: &arpc.StatusPolicy{Mode: arpc.StatusKeep},
+			err:    fmt.Errorf("get book: %w", upstream.Err()),
+
+			expectCode:    codes.NotFound,
+			expectMessage: "get book: rpc error: code = NotFound desc = book not found",
+			expectDetails: true,
+		},
+		{
+			name: "Remap",
+
+			policy: &arpc.StatusPolicy{
+				Mode:  arpc.StatusRemap,
+				Remap: map[codes.Code]codes.Code{codes.NotFound: codes.FailedPrecondition},
+			},
+			err: upstream.Err(),
+
+			expectCode:    codes.FailedPrecondition,
+			expectMessage: "book not found",
+		},
+		{
+			name: "RemapWithDetails",
+
+			policy: &arpc.StatusPolicy{
+				Mode:             arpc.StatusRemap,
+				Remap:            map[codes.Code]codes.Code{codes.NotFound: codes.FailedPrecondition},
+				PropagateDetails: true,
+			},
+			err: upstream.Err(),
+
+			expectCode:    codes.FailedPrecondition,
+			expectMessage: "book not found",
+			expectDetails: true,
+		},
+		{
+			name: "RemapMissing",
+
+			policy: &arpc.StatusPolicy{
+				Mode:  arpc.StatusRemap,
+				Remap: map[codes.Code]codes.Code{codes.Unavailable: codes.Unavailable},
+			},
+			err: upstream.Err(),
+
+			expectCode:    codes.Internal,
+			expectMessage: "book not found",
+		},
+		{
+			name: "NotAStatus",
+
+			policy: &arpc.StatusPolicy{Mode: arpc.StatusKeep},
+			err:    errors.New("uwups"),
+
+			expectCode:    codes.Internal,
+			expectMessage: "uwups",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := arpc.HandleError(codes.Internal)
+			if testCase.policy != nil {
+				handler = handler.Passthrough(*testCase.policy)
+			}
+
+			st, ok := status.FromError(handler.Handle(testCase.err))
+			require.True(t, ok)
+
+			require.Equal(t, testCase.expectCode, st.Code())
+			require.Equal(t, testCase.expectMessage, st.Message())
+
+			if testCase.expectDetails {
+				require.Len(t, st.Details(), 1)
+				require.Equal(t, "BOOK_NOT_FOUND", st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+			} else {
+				require.Empty(t, st.Details())
+			}
+		})
+	}
+
+	t.Run("CasesFirst", func(t *testing.T) {
+		handler := arpc.HandleError(codes.Internal).
+			Passthrough(arpc.StatusPolicy{Mode: arpc.StatusKeep}).
+			Test(func(err error) (error, bool) {
+				if status.Code(err) != codes.NotFound {
+					return nil, false
+				}
+
+				return status.Error(codes.Aborted, "aborted"), true
+			})
+
+		require.Equal(t, codes.Aborted, status.Code(handler.Handle(upstream.Err())))
+	})
+}
@@ -0,0 +1,260 @@
+package arpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnsupportedErrorHandler is returned when merging handlers that were not created by HandleError.
+var ErrUnsupportedErrorHandler = errors.New("error handler was not created by HandleError")
+
+// Code reported for cases that decide the code of the status at runtime.
+const dynamicCode = "dynamic"
+
+// ErrorCase converts an error into a status. Cases are created with the Case* functions, or with NewErrorCase for
+// custom logic. Each case has a name, that identifies it in the traces, counters and mappings of a handler.
+type ErrorCase struct {
+	name  string
+	match string
+	code  string
+
+	fn func(err error) (error, bool)
+}
+
+// Name returns the name of the case.
+func (errorCase ErrorCase) Name() string {
+	return errorCase.name
+}
+
+// Named returns a copy of the case, with the given name.
+func (errorCase ErrorCase) Named(name string) ErrorCase {
+	errorCase.name = name
+	return errorCase
+}
+
+// NewErrorCase creates a case with custom logic. The function returns false if the error does not match the case,
+// so the next cases of the handler are tested.
+func NewErrorCase(name string, fn func(err error) (error, bool)) ErrorCase {
+	return ErrorCase{name: name, match: "custom", code: dynamicCode, fn: fn}
+}
+
+// Returns the name of the type a target of errors.As points to.
+func asTargetName(target interface{}) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", target), "*")
+}
+
+func isCase(target error, code codes.Code, fn func(err error) (error, bool)) ErrorCase {
+	return ErrorCase{name: target.Error(), match: "is " + target.Error(), code: code.String(), fn: fn}
+}
+
+func asCase(target interface{}, code codes.Code, fn func(err error) (error, bool)) ErrorCase {
+	name := asTargetName(target)
+
+	return ErrorCase{name: name, match: "as " + name, code: code.String(), fn: fn}
+}
+
+// CaseIs matches errors with errors.Is, and uses the error message as status message.
+func CaseIs(target error, code codes.Code) ErrorCase {
+	return isCase(target, code, func(err error) (error, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+
+		return status.Errorf(code, "%s", err), true
+	})
+}
+
+// CaseIsW is similar to CaseIs, but joins the wrap error to the status message.
+func CaseIsW(target error, code codes.Code, wrap error) ErrorCase {
+	return isCase(target, code, func(err error) (error, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+
+		return status.Errorf(code, "%s", errors.Join(wrap, err)), true
+	})
+}
+
+// CaseIsWF is similar to CaseIs, but replaces the status message with a formatted one.
+func CaseIsWF(target error, code codes.Code, format string, args ...interface{}) ErrorCase {
+	return isCase(target, code, func(err error) (error, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+
+		return status.Errorf(code, format, args...), true
+	})
+}
+
+// CaseIsD is similar to CaseIs, but the status carries google.rpc details built from the error.
+func CaseIsD(target error, code codes.Code, details ...ErrorDetail) ErrorCase {
+	return isCase(target, code, func(err error) (error, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+
+		return statusWithDetails(code, err, details), true
+	})
+}
+
+// CaseAs matches errors with errors.As, and uses the error message as status message.
+func CaseAs(target interface{}, code codes.Code) ErrorCase {
+	return asCase(target, code, func(err error) (error, bool) {
+		if !errors.As(err, target) {
+			return nil, false
+		}
+
+		return status.Errorf(code, "%s", err), true
+	})
+}
+
+// CaseAsW is similar to CaseAs, but joins the wrap error to the status message.
+func CaseAsW(target interface{}, code codes.Code, wrap error) ErrorCase {
+	return asCase(target, code, func(err error) (error, bool) {
+		if !errors.As(err, target) {
+			return nil, false
+		}
+
+		return status.Errorf(code, "%s", errors.Join(wrap, err)), true
+	})
+}
+
+// CaseAsWF is similar to CaseAs, but replaces the status message with a formatted one.
+func CaseAsWF(target interface{}, code codes.Code, format string, args ...interface{}) ErrorCase {
+	return asCase(target, code, func(err error) (error, bool) {
+		if !errors.As(err, target) {
+			return nil, false
+		}
+
+		return status.Errorf(code, format, args...), true
+	})
+}
+
+// CaseAsD is similar to CaseAs, but the status carries google.rpc details built from the error.
+func CaseAsD(target interface{}, code codes.Code, details ...ErrorDetail) ErrorCase {
+	return asCase(target, code, func(err error) (error, bool) {
+		if !errors.As(err, target) {
+			return nil, false
+		}
+
+		return statusWithDetails(code, err, details), true
+	})
+}
+
+// CaseAsFunc matches errors of type T with errors.As, and lets the matched error build the status, so it can
+// decide the code, message and details of the response. The target is allocated on every call, so the case is safe
+// to use concurrently. When the callback returns a nil or OK status, the error does not match the case, as an OK
+// status would turn the error into a success.
+func CaseAsFunc[T error](build func(target T) *status.Status) ErrorCase {
+	name := reflect.TypeFor[T]().String()
+
+	return ErrorCase{name: name, match: "as " + name, code: dynamicCode, fn: func(err error) (error, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return nil, false
+		}
+
+		st := build(target)
+		if st == nil || st.Code() == codes.OK {
+			return nil, false
+		}
+
+		return st.Err(), true
+	}}
+}
+
+// AsFunc adds a CaseAsFunc to the handler. Go does not support generic methods, hence the function.
+//
+//	handler := arpc.AsFunc(arpc.HandleError(codes.Internal), func(err *QuotaError) *status.Status {
+//		return status.Newf(codes.ResourceExhausted, "quota of %s exceeded", err.Subject)
+//	})
+func AsFunc[T error](handler ErrorHandler, build func(target T) *status.Status) ErrorHandler {
+	return handler.Add(CaseAsFunc(build))
+}
+
+// ErrorCaseGroup is a named, immutable list of cases, that can be included in many handlers. Cases of the group are
+// prefixed with its name, for example "database/no rows".
+//
+//	var DatabaseErrors = arpc.NewErrorCaseGroup(
+//		"database",
+//		arpc.CaseIs(sql.ErrNoRows, codes.NotFound),
+//		arpc.CaseIs(sql.ErrConnDone, codes.Unavailable),
+//	)
+//
+//	handler := arpc.HandleError(codes.Internal).Include(DatabaseErrors)
+type ErrorCaseGroup struct {
+	name  string
+	cases []ErrorCase
+}
+
+// Name returns the name of the group.
+func (group *ErrorCaseGroup) Name() string {
+	return group.name
+}
+
+// NewErrorCaseGroup creates a new group of cases. Cases are tested in order.
+func NewErrorCaseGroup(name string, cases ...ErrorCase) *ErrorCaseGroup {
+	group := &ErrorCaseGroup{name: name, cases: make([]ErrorCase, len(cases))}
+	for i, errorCase := range cases {
+		group.cases[i] = errorCase.Named(name + "/" + errorCase.name)
+	}
+
+	return group
+}
+
+func (errorHandler *errorHandlerImpl) clone() *errorHandlerImpl {
+	return &errorHandlerImpl{
+		cases:        append([]ErrorCase(nil), errorHandler.cases...),
+		defaultCode:  errorHandler.defaultCode,
+		statusPolicy: errorHandler.statusPolicy,
+		sanitizer:    errorHandler.sanitizer,
+		instrument:   errorHandler.instrument,
+	}
+}
+
+func (errorHandler *errorHandlerImpl) Clone() ErrorHandler {
+	return errorHandler.clone()
+}
+
+// Returns a copy of a handler. Handlers not created by HandleError match every error, so cases added after them
+// would never be reached: they are rejected.
+func cloneErrorHandler(handler ErrorHandler) (*errorHandlerImpl, error) {
+	impl, ok := handler.(*errorHandlerImpl)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedErrorHandler, handler)
+	}
+
+	return impl.clone(), nil
+}
+
+// Extend returns a new handler, that tests the cases of base, then the new cases. It is the same as base.Add.
+//
+//	var baseHandler = arpc.HandleError(codes.Internal).Include(DatabaseErrors)
+//
+//	booksHandler := arpc.Extend(baseHandler, arpc.CaseIs(ErrBookNotFound, codes.NotFound))
+func Extend(base ErrorHandler, cases ...ErrorCase) ErrorHandler {
+	return base.Add(cases...)
+}
+
+// Merge returns a new handler, that tests the cases of a, then the cases of b. The default code, status policy,
+// sanitizer and instrumentation of a are used. Both handlers must be created by HandleError.
+func Merge(a, b ErrorHandler) (ErrorHandler, error) {
+	output, err := cloneErrorHandler(a)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := cloneErrorHandler(b)
+	if err != nil {
+		return nil, err
+	}
+
+	output.cases = append(output.cases, other.cases...)
+
+	return output, nil
+}
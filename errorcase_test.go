@@ -0,0 +1,197 @@
+package arpc_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestErrorHandlerComposition(t *testing.T) {
+	var (
+		errNoRows   = errors.New("no rows")
+		errConnDone = errors.New("connection done")
+		errBook     = errors.New("book not found")
+	)
+
+	databaseCases := []arpc.ErrorCase{
+		arpc.CaseIs(errNoRows, codes.NotFound),
+		arpc.CaseIs(errConnDone, codes.Unavailable),
+	}
+
+	databaseErrors := arpc.NewErrorCaseGroup("database", databaseCases...)
+	require.Equal(t, "database", databaseErrors.Name())
+
+	// Groups are not affected by changes to the slice they were created from.
+	databaseCases[0] = arpc.CaseIs(errNoRows, codes.Aborted)
+
+	base := arpc.HandleError(codes.Internal).Include(databaseErrors)
+
+	t.Run("Include", func(t *testing.T) {
+		require.Equal(t, codes.NotFound, status.Code(base.Handle(errNoRows)))
+		require.Equal(t, codes.Unavailable, status.Code(base.Handle(errConnDone)))
+		require.Equal(t, codes.Internal, status.Code(base.Handle(errBook)))
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		clone := base.Clone().Is(errBook, codes.NotFound)
+
+		require.Equal(t, codes.NotFound, status.Code(clone.Handle(errBook)))
+		require.Equal(t, codes.NotFound, status.Code(clone.Handle(errNoRows)))
+		require.Equal(t, codes.Internal, status.Code(base.Handle(errBook)))
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		extended := base.Is(errBook, codes.NotFound).Named("book").Passthrough(arpc.StatusPolicy{Mode: arpc.StatusKeep})
+
+		require.Equal(t, codes.NotFound, status.Code(extended.Handle(errBook)))
+		require.Equal(t, codes.AlreadyExists, status.Code(extended.Handle(status.Error(codes.AlreadyExists, "uwups"))))
+
+		// The base handler is not modified.
+		require.Equal(t, codes.Internal, status.Code(base.Handle(errBook)))
+		require.Equal(t, codes.Internal, status.Code(base.Handle(status.Error(codes.AlreadyExists, "uwups"))))
+		require.Len(t, base.Mappings(), 4)
+
+		// The result of a builder must be used: the receiver never gets the new case.
+		base.Is(errBook, codes.NotFound)
+		require.Equal(t, codes.Internal, status.Code(base.Handle(errBook)))
+		require.Len(t, base.Mappings(), 4)
+	})
+
+	t.Run("Extend", func(t *testing.T) {
+		extended := arpc.Extend(base, arpc.CaseIs(errBook, codes.NotFound), arpc.CaseIs(errNoRows, codes.Aborted))
+
+		require.Equal(t, codes.NotFound, status.Code(extended.Handle(errBook)))
+		// Cases of the base handler come first.
+		require.Equal(t, codes.NotFound, status.Code(extended.Handle(errNoRows)))
+		require.Equal(t, codes.Internal, status.Code(base.Handle(errBook)))
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		other := arpc.HandleError(codes.Unknown).
+			Is(errBook, codes.NotFound).
+			Is(errConnDone, codes.Aborted)
+
+		merged, err := arpc.Merge(base, other)
+		require.NoError(t, err)
+
+		require.Equal(t, codes.NotFound, status.Code(merged.Handle(errBook)))
+		// Cases of the first handler take precedence.
+		require.Equal(t, codes.Unavailable, status.Code(merged.Handle(errConnDone)))
+		// The default code of the first handler is used.
+		require.Equal(t, codes.Internal, status.Code(merged.Handle(errors.New("uwups"))))
+
+		reversed, err := arpc.Merge(other, base)
+		require.NoError(t, err)
+		require.Equal(t, codes.Aborted, status.Code(reversed.Handle(errConnDone)))
+		require.Equal(t, codes.Unknown, status.Code(reversed.Handle(errors.New("uwups"))))
+
+		// Merging does not modify the original handlers.
+		require.Equal(t, codes.Internal, status.Code(base.Handle(errBook)))
+		require.Equal(t, codes.Unknown, status.Code(other.Handle(errNoRows)))
+	})
+
+	t.Run("MergeCustomHandler", func(t *testing.T) {
+		// Custom handlers match every error, so cases composed with them would never be reached.
+		custom := arpcmocks.NewMockErrorHandler(t)
+
+		_, err := arpc.Merge(base, custom)
+		require.ErrorIs(t, err, arpc.ErrUnsupportedErrorHandler)
+
+		_, err = arpc.Merge(custom, base)
+		require.ErrorIs(t, err, arpc.ErrUnsupportedErrorHandler)
+	})
+}
+
+type typedQuotaErr struct {
+	subject string
+	limit   int
+}
+
+func (e *typedQuotaErr) Error() string {
+	return fmt.Sprintf("quota of %d exceeded for %s", e.limit, e.subject)
+}
+
+func TestAsFunc(t *testing.T) {
+	handler := arpc.AsFunc(arpc.HandleError(codes.Internal), func(err *typedQuotaErr) *status.Status {
+		// Let other cases handle unlimited quotas.
+		if err.limit == 0 {
+			return nil
+		}
+
+		st, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(&errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{Subject: err.subject}},
+		})
+		require.NoError(t, detailsErr)
+
+		return st
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		st := status.Convert(handler.Handle(fmt.Errorf("wrapped: %w", &typedQuotaErr{subject: "foo", limit: 10})))
+
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Equal(t, "quota of 10 exceeded for foo", st.Message())
+		require.Len(t, st.Details(), 1)
+		require.Equal(t, "foo", st.Details()[0].(*errdetails.QuotaFailure).GetViolations()[0].GetSubject())
+	})
+
+	t.Run("Declined", func(t *testing.T) {
+		require.Equal(t, codes.Internal, status.Code(handler.Handle(&typedQuotaErr{subject: "foo"})))
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		require.Equal(t, codes.Internal, status.Code(handler.Handle(errors.New("uwups"))))
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		okHandler := arpc.AsFunc(arpc.HandleError(codes.Internal), func(_ *typedQuotaErr) *status.Status {
+			return status.New(codes.OK, "")
+		})
+
+		// An OK status must not turn the error into a success.
+		err := okHandler.Handle(&typedQuotaErr{subject: "foo", limit: 1})
+		require.Error(t, err)
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		wg := new(sync.WaitGroup)
+
+		for i := range 50 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				subject := strconv.Itoa(i)
+				st := status.Convert(handler.Handle(&typedQuotaErr{subject: subject, limit: 1}))
+
+				assert.Equal(t, "quota of 1 exceeded for "+subject, st.Message())
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("Case", func(t *testing.T) {
+		extended := arpc.Extend(
+			arpc.HandleError(codes.Internal),
+			arpc.CaseAsFunc(func(err *typedQuotaErr) *status.Status {
+				return status.New(codes.ResourceExhausted, err.subject)
+			}),
+		)
+
+		require.Equal(t, codes.ResourceExhausted, status.Code(extended.Handle(&typedQuotaErr{subject: "foo"})))
+	})
+}
@@ -0,0 +1,220 @@
+package arpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrMissingReason   = errors.New("missing catalog reason")
+	ErrDuplicateReason = errors.New("duplicate catalog reason")
+)
+
+// CatalogEntry describes an error shared across services. Entries are sentinel errors, that can be returned as is,
+// or formatted with New.
+//
+//	var ErrBookNotFound = &arpc.CatalogEntry{
+//		Reason:   "BOOK_NOT_FOUND",
+//		Domain:   "books.a-novel.app",
+//		Code:     codes.NotFound,
+//		Message:  "book not found",
+//		Template: "book %s not found",
+//	}
+type CatalogEntry struct {
+	// Reason identifies the error across services, in UPPER_SNAKE_CASE. It must never change once published, as
+	// clients rely on it.
+	Reason string
+	// Domain is the service that owns the error, for example "books.a-novel.app".
+	Domain string
+	// Code is the GRPC code of the statuses built from the error.
+	Code codes.Code
+	// HTTPCode is the HTTP status code of the error, when served by a Gateway. Defaults to the code matching Code.
+	HTTPCode int
+	// Retryable tells clients the call can be retried as is, after RetryDelay.
+	Retryable  bool
+	RetryDelay time.Duration
+	// Message is the message of the entry, when returned as is. It must not contain formatting verbs.
+	Message string
+	// Template is optional. It is the message of the errors created with New, using the syntax of fmt.Sprintf.
+	Template string
+}
+
+func (entry *CatalogEntry) Error() string {
+	return entry.Message
+}
+
+// New returns an error matching the entry with errors.Is, with a message formatted from the template. Entries
+// without a template use their message.
+func (entry *CatalogEntry) New(args ...interface{}) error {
+	if entry.Template == "" {
+		return &CatalogError{entry: entry, message: entry.Message}
+	}
+
+	return &CatalogError{entry: entry, message: fmt.Sprintf(entry.Template, args...)}
+}
+
+func (entry *CatalogEntry) httpCode() int {
+	if entry.HTTPCode == 0 {
+		return HTTPStatusFromCode(entry.Code)
+	}
+
+	return entry.HTTPCode
+}
+
+func (entry *CatalogEntry) details() []ErrorDetail {
+	details := []ErrorDetail{DetailErrorInfo(entry.Reason, entry.Domain, nil)}
+	if entry.Retryable {
+		details = append(details, DetailRetryInfo(entry.RetryDelay))
+	}
+
+	return details
+}
+
+// CatalogError is an error created from a CatalogEntry, either with CatalogEntry.New, or by the decoder of a catalog.
+type CatalogError struct {
+	entry   *CatalogEntry
+	message string
+}
+
+func (e *CatalogError) Error() string {
+	return e.message
+}
+
+func (e *CatalogError) Unwrap() error {
+	return e.entry
+}
+
+// Entry returns the entry the error was created from.
+func (e *CatalogError) Entry() *CatalogEntry {
+	return e.entry
+}
+
+// ErrorCatalog lists the errors shared by services. Each entry generates a case for the server ErrorHandler, and a
+// case for the client ErrorDecoder, so both sides agree on codes and reasons.
+//
+//	catalog, err := arpc.NewErrorCatalog(ErrBookNotFound, ErrQuotaExceeded)
+//
+//	// Server side.
+//	handler := arpc.HandleError(codes.Internal).Include(catalog.Group("books"))
+//	// Client side.
+//	decoder := catalog.Decoder()
+type ErrorCatalog struct {
+	entries []*CatalogEntry
+}
+
+// NewErrorCatalog creates a new catalog. Reasons are required, and must be unique, as the client decoder only reads
+// the reason of statuses.
+func NewErrorCatalog(entries ...*CatalogEntry) (*ErrorCatalog, error) {
+	reasons := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		if entry.Reason == "" {
+			return nil, fmt.Errorf("%w: %q", ErrMissingReason, entry.Message)
+		}
+
+		if reasons[entry.Reason] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateReason, entry.Reason)
+		}
+
+		reasons[entry.Reason] = true
+	}
+
+	return &ErrorCatalog{entries: append([]*CatalogEntry(nil), entries...)}, nil
+}
+
+// Entries returns the entries of the catalog, in order.
+func (catalog *ErrorCatalog) Entries() []*CatalogEntry {
+	return append([]*CatalogEntry(nil), catalog.entries...)
+}
+
+// Group returns the server side cases of the catalog. Statuses built from an entry carry an ErrorInfo with its
+// reason and domain, and a RetryInfo if it is retryable. Cases are named after the reasons of the entries.
+func (catalog *ErrorCatalog) Group(name string) *ErrorCaseGroup {
+	cases := make([]ErrorCase, len(catalog.entries))
+	for i, entry := range catalog.entries {
+		cases[i] = CaseIsD(entry, entry.Code, entry.details()...).Named(entry.Reason)
+	}
+
+	return NewErrorCaseGroup(name, cases...)
+}
+
+// Decoder returns the client side decoder of the catalog. Decoded errors are CatalogError, that match their entry
+// with errors.Is, and keep the message of the status.
+func (catalog *ErrorCatalog) Decoder() ErrorDecoder {
+	decoder := DecodeError()
+
+	for _, entry := range catalog.entries {
+		decoder = decoder.As(entry.Code, entry.Reason, func(st *status.Status) error {
+			return &CatalogError{entry: entry, message: st.Message()}
+		})
+	}
+
+	return decoder
+}
+
+// Returns the HTTP status code of a status built from an entry of the catalog, if any.
+func (catalog *ErrorCatalog) httpCode(st *status.Status) (int, bool) {
+	if catalog == nil {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+
+		for _, entry := range catalog.entries {
+			if entry.Reason == info.GetReason() && entry.Domain == info.GetDomain() {
+				return entry.httpCode(), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+type catalogEntryJSON struct {
+	Reason     string `json:"reason"`
+	Domain     string `json:"domain"`
+	Code       string `json:"code"`
+	GRPCCode   int    `json:"grpcCode"`
+	HTTPCode   int    `json:"httpCode"`
+	Retryable  bool   `json:"retryable"`
+	RetryDelay string `json:"retryDelay,omitempty"`
+	Message    string `json:"message"`
+	Template   string `json:"template,omitempty"`
+}
+
+// MarshalJSON exports the catalog, so client SDKs can be generated from it.
+//
+//	[{"reason": "BOOK_NOT_FOUND", "domain": "books.a-novel.app", "code": "NotFound", "grpcCode": 5, "httpCode": 404,
+//	"retryable": false, "message": "book not found", "template": "book %s not found"}]
+func (catalog *ErrorCatalog) MarshalJSON() ([]byte, error) {
+	output := make([]catalogEntryJSON, len(catalog.entries))
+
+	for i, entry := range catalog.entries {
+		output[i] = catalogEntryJSON{
+			Reason:    entry.Reason,
+			Domain:    entry.Domain,
+			Code:      entry.Code.String(),
+			GRPCCode:  int(entry.Code),
+			HTTPCode:  entry.httpCode(),
+			Retryable: entry.Retryable,
+			Message:   entry.Message,
+			Template:  entry.Template,
+		}
+
+		if entry.Retryable && entry.RetryDelay > 0 {
+			output[i].RetryDelay = entry.RetryDelay.String()
+		}
+	}
+
+	return json.Marshal(output) //nolint:wrapcheck
+}
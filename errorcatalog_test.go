@@ -0,0 +1,178 @@
+package arpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestErrorCatalog(t *testing.T) {
+	errBookNotFound := &arpc.CatalogEntry{
+		Reason:   "BOOK_NOT_FOUND",
+		Domain:   "books.a-novel.app",
+		Code:     codes.NotFound,
+		Message:  "book not found",
+		Template: "book %s not found",
+	}
+	errBookArchived := &arpc.CatalogEntry{
+		Reason:   "BOOK_ARCHIVED",
+		Domain:   "books.a-novel.app",
+		Code:     codes.FailedPrecondition,
+		HTTPCode: http.StatusGone,
+		Message:  "book is archived",
+	}
+	errQuotaExceeded := &arpc.CatalogEntry{
+		Reason:     "QUOTA_EXCEEDED",
+		Domain:     "quotas.a-novel.app",
+		Code:       codes.ResourceExhausted,
+		Retryable:  true,
+		RetryDelay: time.Minute,
+		Message:    "quota exceeded",
+	}
+
+	catalog, err := arpc.NewErrorCatalog(errBookNotFound, errBookArchived, errQuotaExceeded)
+	require.NoError(t, err)
+
+	handler := arpc.HandleError(codes.Internal).Include(catalog.Group("catalog"))
+	decoder := catalog.Decoder()
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := arpc.NewErrorCatalog(errBookNotFound, &arpc.CatalogEntry{Message: "no reason"})
+		require.ErrorIs(t, err, arpc.ErrMissingReason)
+
+		_, err = arpc.NewErrorCatalog(errBookNotFound, &arpc.CatalogEntry{Reason: "BOOK_NOT_FOUND", Domain: "other"})
+		require.ErrorIs(t, err, arpc.ErrDuplicateReason)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		st := status.Convert(handler.Handle(fmt.Errorf("load: %w", errBookNotFound.New("foo"))))
+
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, "load: book foo not found", st.Message())
+		require.Len(t, st.Details(), 1)
+		require.Equal(t, "BOOK_NOT_FOUND", st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+		require.Equal(t, "books.a-novel.app", st.Details()[0].(*errdetails.ErrorInfo).GetDomain())
+
+		st = status.Convert(handler.Handle(errQuotaExceeded))
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 2)
+		require.Equal(t, time.Minute, st.Details()[1].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+
+		require.Equal(t, "catalog/BOOK_ARCHIVED", handler.Explain(errBookArchived).Matched)
+
+		// Entries returned as is use their message, without formatting verbs.
+		st = status.Convert(handler.Handle(errBookNotFound))
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, "book not found", st.Message())
+
+		// Entries without a template ignore the arguments of New.
+		require.Equal(t, "book is archived", errBookArchived.New("foo").Error())
+	})
+
+	t.Run("Decoder", func(t *testing.T) {
+		decoded := decoder.Decode(handler.Handle(errBookNotFound.New("foo")))
+
+		require.ErrorIs(t, decoded, errBookNotFound)
+		require.NotErrorIs(t, decoded, errBookArchived)
+		require.Equal(t, codes.NotFound, status.Code(decoded))
+
+		var catalogErr *arpc.CatalogError
+		require.ErrorAs(t, decoded, &catalogErr)
+		require.Equal(t, errBookNotFound, catalogErr.Entry())
+		require.Equal(t, "book foo not found", catalogErr.Error())
+
+		// Statuses with the same code but without the reason are not decoded.
+		require.NotErrorIs(t, decoder.Decode(status.Error(codes.NotFound, "nope")), errBookNotFound)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(catalog)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `[
+			{
+				"reason": "BOOK_NOT_FOUND",
+				"domain": "books.a-novel.app",
+				"code": "NotFound",
+				"grpcCode": 5,
+				"httpCode": 404,
+				"retryable": false,
+				"message": "book not found",
+				"template": "book %s not found"
+			},
+			{
+				"reason": "BOOK_ARCHIVED",
+				"domain": "books.a-novel.app",
+				"code": "FailedPrecondition",
+				"grpcCode": 9,
+				"httpCode": 410,
+				"retryable": false,
+				"message": "book is archived"
+			},
+			{
+				"reason": "QUOTA_EXCEEDED",
+				"domain": "quotas.a-novel.app",
+				"code": "ResourceExhausted",
+				"grpcCode": 8,
+				"httpCode": 429,
+				"retryable": true,
+				"retryDelay": "1m0s",
+				"message": "quota exceeded"
+			}
+		]`, string(data))
+	})
+
+	t.Run("Gateway", func(t *testing.T) {
+		stub := &arpcmocks.StubServer{
+			UnaryCallF: func(_ context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+				if in.GetResponseSize() > 0 {
+					return nil, handler.Handle(errBookArchived)
+				}
+
+				return nil, handler.Handle(errBookNotFound.New("foo"))
+			},
+		}
+
+		addr := startGateway(t, stub, &arpc.GatewayConfig{Catalog: catalog})
+
+		for body, expect := range map[string]int{
+			`{"responseSize": 1}`: http.StatusGone,
+			`{"responseSize": 0}`: http.StatusNotFound,
+		} {
+			req, err := http.NewRequestWithContext(
+				context.Background(), http.MethodPost, "http://"+addr+"/grpc.testing.TestService/UnaryCall",
+				strings.NewReader(body),
+			)
+			require.NoError(t, err)
+
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, expect, res.StatusCode, body)
+		}
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		entries := catalog.Entries()
+		entries[0] = errQuotaExceeded
+
+		require.Equal(t, errBookNotFound, catalog.Entries()[0])
+		require.True(t, errors.Is(errBookNotFound.New("bar"), errBookNotFound))
+	})
+}
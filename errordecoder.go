@@ -0,0 +1,166 @@
+package arpc
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StatusError is returned by an ErrorDecoder, when a status matches one of its cases. It wraps the decoded error,
+// so it can be checked with errors.Is and errors.As, and still carries the original status, so status.FromError
+// and status.Code keep working.
+type StatusError struct {
+	status *status.Status
+	err    error
+}
+
+func (e *StatusError) Error() string {
+	return e.status.Err().Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the original status.
+func (e *StatusError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// ErrorDecoder is the client side counterpart of ErrorHandler. It turns status errors returned by GRPC calls back
+// into domain errors. Like handlers, decoders are immutable: Is and As return a modified copy, and leave the
+// receiver untouched.
+type ErrorDecoder interface {
+	// Is decodes statuses with the given code and reason into a sentinel error. The reason is read from the
+	// ErrorInfo detail of the status, as set by ErrorHandler.IsD with DetailErrorInfo. An empty reason matches any
+	// status with the given code.
+	Is(code codes.Code, reason string, target error) ErrorDecoder
+	// As decodes statuses with the given code and reason into the error returned by the constructor. Typed errors
+	// can then be retrieved with errors.As.
+	As(code codes.Code, reason string, constructor func(st *status.Status) error) ErrorDecoder
+
+	// Decode returns a StatusError if the error is a status that matches one of the cases. Other errors are
+	// returned as is.
+	Decode(err error) error
+
+	// UnaryClientInterceptor decodes the errors of unary calls.
+	UnaryClientInterceptor() grpc.UnaryClientInterceptor
+	// StreamClientInterceptor decodes the errors of streams.
+	StreamClientInterceptor() grpc.StreamClientInterceptor
+}
+
+type errorDecoderCase struct {
+	code        codes.Code
+	reason      string
+	constructor func(st *status.Status) error
+}
+
+type errorDecoderImpl struct {
+	cases []errorDecoderCase
+}
+
+func (decoder *errorDecoderImpl) Is(code codes.Code, reason string, target error) ErrorDecoder {
+	return decoder.As(code, reason, func(_ *status.Status) error { return target })
+}
+
+func (decoder *errorDecoderImpl) As(
+	code codes.Code, reason string, constructor func(st *status.Status) error,
+) ErrorDecoder {
+	// Clip the cases, so the receiver and the copy never share their backing array.
+	return &errorDecoderImpl{
+		cases: append(slices.Clip(decoder.cases), errorDecoderCase{code: code, reason: reason, constructor: constructor}),
+	}
+}
+
+// StatusReason returns the reason of the ErrorInfo detail carried by a status, if any.
+func StatusReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+
+	return ""
+}
+
+func (decoder *errorDecoderImpl) Decode(err error) error {
+	// Don't decode twice.
+	var statusErr *StatusError
+	if err == nil || errors.As(err, &statusErr) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	reason := StatusReason(st)
+
+	for _, decoderCase := range decoder.cases {
+		if decoderCase.code != st.Code() || (decoderCase.reason != "" && decoderCase.reason != reason) {
+			continue
+		}
+
+		return &StatusError{status: st, err: decoderCase.constructor(st)}
+	}
+
+	return err
+}
+
+func (decoder *errorDecoderImpl) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return decoder.Decode(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+type decodedClientStream struct {
+	grpc.ClientStream
+
+	decoder *errorDecoderImpl
+}
+
+func (stream *decodedClientStream) SendMsg(m interface{}) error {
+	return stream.decoder.Decode(stream.ClientStream.SendMsg(m))
+}
+
+func (stream *decodedClientStream) RecvMsg(m interface{}) error {
+	return stream.decoder.Decode(stream.ClientStream.RecvMsg(m))
+}
+
+func (decoder *errorDecoderImpl) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, decoder.Decode(err)
+		}
+
+		return &decodedClientStream{ClientStream: stream, decoder: decoder}, nil
+	}
+}
+
+// DecodeError creates a new error decoder. Cases are tested in the order they are registered, so register cases
+// with a reason before the catch-all case of the same code. Decoders are immutable, and safe for concurrent use.
+//
+//	decoder := arpc.DecodeError().
+//		Is(codes.NotFound, "BOOK_NOT_FOUND", ErrBookNotFound).
+//		Is(codes.NotFound, "", ErrNotFound)
+//
+//	pool := arpc.NewConnPool(release,
+//		grpc.WithChainUnaryInterceptor(decoder.UnaryClientInterceptor()),
+//		grpc.WithChainStreamInterceptor(decoder.StreamClientInterceptor()),
+//	)
+func DecodeError() ErrorDecoder {
+	return new(errorDecoderImpl)
+}
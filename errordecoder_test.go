@@ -0,0 +1,148 @@
+package arpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestErrorDecoder(t *testing.T) {
+	var (
+		errBookNotFound = errors.New("book not found")
+		errNotFound     = errors.New("not found")
+		errQuota        = errors.New("quota exceeded")
+	)
+
+	// Server side mapping.
+	handler := arpc.HandleError(codes.Internal).
+		IsD(errBookNotFound, codes.NotFound, arpc.DetailErrorInfo("BOOK_NOT_FOUND", "library.example.com", nil)).
+		Is(errNotFound, codes.NotFound).
+		Is(errQuota, codes.ResourceExhausted)
+
+	decoder := arpc.DecodeError().
+		Is(codes.NotFound, "BOOK_NOT_FOUND", errBookNotFound).
+		Is(codes.NotFound, "", errNotFound).
+		As(codes.ResourceExhausted, "", func(st *status.Status) error {
+			return NewFooErr(st.Message())
+		})
+
+	// The error returned by the server is selected with the response size of the request.
+	serverErrors := []error{errBookNotFound, errNotFound, errQuota, errors.New("uwups")}
+
+	stub := &arpcmocks.StubServer{
+		UnaryCallF: func(_ context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return nil, handler.Handle(serverErrors[in.GetResponseSize()])
+		},
+		FullDuplexCallF: func(_ testgrpc.TestService_FullDuplexCallServer) error {
+			return handler.Handle(errBookNotFound)
+		},
+	}
+
+	require.NoError(t, stub.Start(nil))
+	defer stub.Stop()
+
+	_, rawPort, err := net.SplitHostPort(stub.Address)
+	require.NoError(t, err)
+
+	port, err := strconv.Atoi(rawPort)
+	require.NoError(t, err)
+
+	connPool := arpc.NewConnPool(
+		false,
+		grpc.WithChainUnaryInterceptor(decoder.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(decoder.StreamClientInterceptor()),
+	)
+	defer connPool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := connPool.Open(ctx, "localhost", port, arpc.ProtocolHTTP)
+	require.NoError(t, err)
+
+	client := testgrpc.NewTestServiceClient(conn)
+
+	call := func(errIndex int32) error {
+		_, err := client.UnaryCall(ctx, &testgrpc.SimpleRequest{ResponseSize: errIndex})
+		return err
+	}
+
+	t.Run("Reason", func(t *testing.T) {
+		err := call(0)
+
+		require.ErrorIs(t, err, errBookNotFound)
+		require.NotErrorIs(t, err, errNotFound)
+		require.Equal(t, codes.NotFound, status.Code(err))
+		require.Equal(t, "BOOK_NOT_FOUND", arpc.StatusReason(status.Convert(err)))
+
+		var statusErr *arpc.StatusError
+		require.ErrorAs(t, err, &statusErr)
+	})
+
+	t.Run("AnyReason", func(t *testing.T) {
+		err := call(1)
+
+		require.ErrorIs(t, err, errNotFound)
+		require.Equal(t, codes.NotFound, status.Code(err))
+		require.Equal(t, "not found", status.Convert(err).Message())
+	})
+
+	t.Run("Typed", func(t *testing.T) {
+		err := call(2)
+
+		var fooErr *FooErr
+		require.ErrorAs(t, err, &fooErr)
+		require.Equal(t, "quota exceeded", fooErr.message)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		err := call(3)
+
+		var statusErr *arpc.StatusError
+		require.False(t, errors.As(err, &statusErr))
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream, err := client.FullDuplexCall(ctx)
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, errBookNotFound)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		base := arpc.DecodeError().Is(codes.ResourceExhausted, "", errQuota)
+
+		// Both decoders extend the same base, without seeing each other cases.
+		decoderA := base.Is(codes.NotFound, "", errNotFound)
+		decoderB := base.Is(codes.NotFound, "", errBookNotFound)
+
+		notFound := status.Error(codes.NotFound, "not found")
+
+		require.ErrorIs(t, decoderA.Decode(notFound), errNotFound)
+		require.ErrorIs(t, decoderB.Decode(notFound), errBookNotFound)
+		require.Equal(t, notFound, base.Decode(notFound))
+		require.ErrorIs(t, base.Decode(status.Error(codes.ResourceExhausted, "quota exceeded")), errQuota)
+	})
+
+	t.Run("NonStatus", func(t *testing.T) {
+		err := errors.New("uwups")
+		require.Equal(t, err, decoder.Decode(err))
+		require.NoError(t, decoder.Decode(nil))
+	})
+}
@@ -0,0 +1,120 @@
+package arpc
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDetail builds a google.rpc detail from the error matched by an ErrorHandler case, so clients can handle the
+// failure without parsing the message. It may return nil to omit the detail.
+type ErrorDetail func(err error) protoadapt.MessageV1
+
+// DetailErrorInfo attaches an ErrorInfo, describing the cause of the error with a machine-readable reason, in a given
+// domain (usually the name of the service). The metadata function is optional.
+func DetailErrorInfo(reason, domain string, metadata func(err error) map[string]string) ErrorDetail {
+	return func(err error) protoadapt.MessageV1 {
+		info := &errdetails.ErrorInfo{Reason: reason, Domain: domain}
+		if metadata != nil {
+			info.Metadata = metadata(err)
+		}
+
+		return info
+	}
+}
+
+// DetailBadRequest attaches a BadRequest, listing the fields at fault. When violations is nil, they are read from
+// the error, the same way UnaryValidationInterceptor does for errors generated by protoc-gen-validate.
+func DetailBadRequest(violations func(err error) []*errdetails.BadRequest_FieldViolation) ErrorDetail {
+	return func(err error) protoadapt.MessageV1 {
+		if violations == nil {
+			return &errdetails.BadRequest{FieldViolations: fieldViolations(err, "")}
+		}
+
+		return &errdetails.BadRequest{FieldViolations: violations(err)}
+	}
+}
+
+// DetailRetryInfo attaches a RetryInfo, telling the client how long to wait before retrying.
+func DetailRetryInfo(delay time.Duration) ErrorDetail {
+	return func(_ error) protoadapt.MessageV1 {
+		return &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}
+	}
+}
+
+// DetailPreconditionFailure attaches a PreconditionFailure, listing the preconditions the request did not meet.
+// The detail is omitted when violations is nil.
+func DetailPreconditionFailure(violations func(err error) []*errdetails.PreconditionFailure_Violation) ErrorDetail {
+	return func(err error) protoadapt.MessageV1 {
+		if violations == nil {
+			return nil
+		}
+
+		return &errdetails.PreconditionFailure{Violations: violations(err)}
+	}
+}
+
+// DetailResourceInfo attaches a ResourceInfo, describing the resource the request failed to access. The name of
+// the resource is read from the error, and the error message is used as description. The name function is optional.
+func DetailResourceInfo(resourceType string, name func(err error) string) ErrorDetail {
+	return func(err error) protoadapt.MessageV1 {
+		info := &errdetails.ResourceInfo{ResourceType: resourceType, Description: err.Error()}
+		if name != nil {
+			info.ResourceName = name(err)
+		}
+
+		return info
+	}
+}
+
+// DetailQuotaFailure attaches a QuotaFailure, listing the quotas the request exceeded. The detail is omitted when
+// violations is nil.
+func DetailQuotaFailure(violations func(err error) []*errdetails.QuotaFailure_Violation) ErrorDetail {
+	return func(err error) protoadapt.MessageV1 {
+		if violations == nil {
+			return nil
+		}
+
+		return &errdetails.QuotaFailure{Violations: violations(err)}
+	}
+}
+
+// DetailLocalizedMessage attaches a LocalizedMessage, that can be shown to end users. The locale follows the
+// syntax of BCP 47, for example "en-US". When message is nil, the error message is used.
+func DetailLocalizedMessage(locale string, message func(err error) string) ErrorDetail {
+	return func(err error) protoadapt.MessageV1 {
+		if message == nil {
+			return &errdetails.LocalizedMessage{Locale: locale, Message: err.Error()}
+		}
+
+		return &errdetails.LocalizedMessage{Locale: locale, Message: message(err)}
+	}
+}
+
+// Creates a status for the error, carrying the details built from it.
+func statusWithDetails(code codes.Code, err error, details []ErrorDetail) error {
+	st := status.New(code, err.Error())
+
+	messages := make([]protoadapt.MessageV1, 0, len(details))
+
+	for _, detail := range details {
+		if message := detail(err); message != nil {
+			messages = append(messages, message)
+		}
+	}
+
+	if len(messages) == 0 {
+		return st.Err() //nolint:wrapcheck
+	}
+
+	withDetails, detailsErr := st.WithDetails(messages...)
+	if detailsErr != nil {
+		return st.Err() //nolint:wrapcheck
+	}
+
+	return withDetails.Err() //nolint:wrapcheck
+}
@@ -0,0 +1,210 @@
+package arpc_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/a-novel-kit/arpc"
+)
+
+type quotaErr struct {
+	subject string
+}
+
+func (e *quotaErr) Error() string {
+	return "quota exceeded for " + e.subject
+}
+
+func TestHandleErrorDetails(t *testing.T) {
+	var (
+		errNotFound     = errors.New("not found")
+		errInvalid      = errors.New("invalid")
+		errPrecondition = errors.New("precondition failed")
+		errUnavailable  = errors.New("unavailable")
+	)
+
+	handler := arpc.HandleError(codes.Internal).
+		IsD(
+			errNotFound, codes.NotFound,
+			arpc.DetailErrorInfo("BOOK_NOT_FOUND", "library.example.com", func(err error) map[string]string {
+				return map[string]string{"cause": err.Error()}
+			}),
+			arpc.DetailResourceInfo("library.example.com/Book", func(_ error) string { return "books/42" }),
+			arpc.DetailLocalizedMessage("en-US", nil),
+		).
+		IsD(errInvalid, codes.InvalidArgument, arpc.DetailBadRequest(nil)).
+		IsD(
+			errPrecondition, codes.FailedPrecondition,
+			arpc.DetailPreconditionFailure(func(err error) []*errdetails.PreconditionFailure_Violation {
+				return []*errdetails.PreconditionFailure_Violation{{Type: "TOS", Subject: "user", Description: err.Error()}}
+			}),
+		).
+		IsD(
+			errUnavailable, codes.Unavailable,
+			arpc.DetailRetryInfo(time.Second),
+			// Nil details are omitted.
+			func(_ error) protoadapt.MessageV1 { return nil },
+		).
+		AsD(
+			new(*quotaErr), codes.ResourceExhausted,
+			arpc.DetailQuotaFailure(func(err error) []*errdetails.QuotaFailure_Violation {
+				var target *quotaErr
+				errors.As(err, &target)
+
+				return []*errdetails.QuotaFailure_Violation{{Subject: target.subject, Description: err.Error()}}
+			}),
+		)
+
+	testCases := []struct {
+		name string
+
+		err error
+
+		expectCode    codes.Code
+		expectMessage string
+		expectDetails []proto.Message
+	}{
+		{
+			name: "ErrorInfo",
+
+			err: fmt.Errorf("book 42: %w", errNotFound),
+
+			expectCode:    codes.NotFound,
+			expectMessage: "book 42: not found",
+			expectDetails: []proto.Message{
+				&errdetails.ErrorInfo{
+					Reason:   "BOOK_NOT_FOUND",
+					Domain:   "library.example.com",
+					Metadata: map[string]string{"cause": "book 42: not found"},
+				},
+				&errdetails.ResourceInfo{
+					ResourceType: "library.example.com/Book",
+					ResourceName: "books/42",
+					Description:  "book 42: not found",
+				},
+				&errdetails.LocalizedMessage{Locale: "en-US", Message: "book 42: not found"},
+			},
+		},
+		{
+			name: "BadRequest",
+
+			err: errors.Join(errInvalid, errors.New("title is required")),
+
+			expectCode:    codes.InvalidArgument,
+			expectMessage: "invalid\ntitle is required",
+			expectDetails: []proto.Message{
+				&errdetails.BadRequest{
+					FieldViolations: []*errdetails.BadRequest_FieldViolation{
+						{Description: "invalid"},
+						{Description: "title is required"},
+					},
+				},
+			},
+		},
+		{
+			name: "PreconditionFailure",
+
+			err: errPrecondition,
+
+			expectCode:    codes.FailedPrecondition,
+			expectMessage: "precondition failed",
+			expectDetails: []proto.Message{
+				&errdetails.PreconditionFailure{
+					Violations: []*errdetails.PreconditionFailure_Violation{
+						{Type: "TOS", Subject: "user", Description: "precondition failed"},
+					},
+				},
+			},
+		},
+		{
+			name: "RetryInfo",
+
+			err: errUnavailable,
+
+			expectCode:    codes.Unavailable,
+			expectMessage: "unavailable",
+			expectDetails: []proto.Message{
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)},
+			},
+		},
+		{
+			name: "QuotaFailure",
+
+			err: &quotaErr{subject: "project:foo"},
+
+			expectCode:    codes.ResourceExhausted,
+			expectMessage: "quota exceeded for project:foo",
+			expectDetails: []proto.Message{
+				&errdetails.QuotaFailure{
+					Violations: []*errdetails.QuotaFailure_Violation{
+						{Subject: "project:foo", Description: "quota exceeded for project:foo"},
+					},
+				},
+			},
+		},
+		{
+			name: "Default",
+
+			err: errors.New("uwups"),
+
+			expectCode:    codes.Internal,
+			expectMessage: "uwups",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			st, ok := status.FromError(handler.Handle(testCase.err))
+			require.True(t, ok)
+
+			require.Equal(t, testCase.expectCode, st.Code())
+			require.Equal(t, testCase.expectMessage, st.Message())
+
+			details := st.Details()
+			require.Len(t, details, len(testCase.expectDetails))
+
+			for i, expectDetail := range testCase.expectDetails {
+				detail, ok := details[i].(proto.Message)
+				require.True(t, ok, details[i])
+				require.True(t, proto.Equal(expectDetail, detail), "expected %v, got %v", expectDetail, detail)
+			}
+		})
+	}
+}
+
+func TestErrorDetailsNilFunc(t *testing.T) {
+	err := errors.New("uwups")
+
+	require.Nil(t, arpc.DetailPreconditionFailure(nil)(err))
+	require.Nil(t, arpc.DetailQuotaFailure(nil)(err))
+
+	require.True(t, proto.Equal(
+		&errdetails.ResourceInfo{ResourceType: "library.example.com/Book", Description: "uwups"},
+		protoadapt.MessageV2Of(arpc.DetailResourceInfo("library.example.com/Book", nil)(err)),
+	))
+	require.True(t, proto.Equal(
+		&errdetails.ErrorInfo{Reason: "UWUPS", Domain: "library.example.com"},
+		protoadapt.MessageV2Of(arpc.DetailErrorInfo("UWUPS", "library.example.com", nil)(err)),
+	))
+	require.True(t, proto.Equal(
+		&errdetails.LocalizedMessage{Locale: "en-US", Message: "uwups"},
+		protoadapt.MessageV2Of(arpc.DetailLocalizedMessage("en-US", nil)(err)),
+	))
+
+	// Builders with a nil function still work in a handler.
+	st := status.Convert(arpc.HandleError(codes.Internal).
+		IsD(err, codes.FailedPrecondition, arpc.DetailPreconditionFailure(nil), arpc.DetailQuotaFailure(nil)).
+		Handle(err))
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+	require.Empty(t, st.Details())
+}
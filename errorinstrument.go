@@ -0,0 +1,141 @@
+package arpc
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+// Names given to the results of an ErrorHandler that don't come from a case.
+const (
+	passthroughCaseName = "passthrough"
+	defaultCaseName     = "default"
+)
+
+// ErrorTrace describes how an ErrorHandler handled an error.
+type ErrorTrace struct {
+	// Err is the error passed to the handler.
+	Err error
+	// Result is the status returned by the handler.
+	Result error
+	// Evaluated lists the names of the cases tested, in order.
+	Evaluated []string
+	// Matched is the name of the case that produced the result. It is "passthrough" for statuses that matched no
+	// case, and "default" for other errors that matched no case.
+	Matched string
+}
+
+// ErrorCounterKey identifies a counter of an ErrorCounter.
+type ErrorCounterKey struct {
+	Case string
+	Code codes.Code
+}
+
+// ErrorCounter counts the errors handled by an ErrorHandler, by case and resulting code. It is thread-safe, and
+// can be polled to trigger alerts.
+type ErrorCounter struct {
+	counts map[ErrorCounterKey]int
+	mu     sync.RWMutex
+}
+
+func (counter *ErrorCounter) inc(key ErrorCounterKey) {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
+	if counter.counts == nil {
+		counter.counts = make(map[ErrorCounterKey]int)
+	}
+
+	counter.counts[key]++
+}
+
+// Count returns the number of errors handled by a case, that resulted in the given code.
+func (counter *ErrorCounter) Count(caseName string, code codes.Code) int {
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+
+	return counter.counts[ErrorCounterKey{Case: caseName, Code: code}]
+}
+
+// Counts returns a snapshot of every counter.
+func (counter *ErrorCounter) Counts() map[ErrorCounterKey]int {
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+
+	output := make(map[ErrorCounterKey]int, len(counter.counts))
+	for key, count := range counter.counts {
+		output[key] = count
+	}
+
+	return output
+}
+
+// ErrorInstrumentation configures the instrumentation of an ErrorHandler.
+type ErrorInstrumentation struct {
+	// Counter is optional. When set, it is updated every time an error is handled.
+	Counter *ErrorCounter
+	// Logger receives a trace of every error handled, when Trace is set.
+	Logger quicklog.Logger
+	// Trace logs the cases evaluated for every error handled. It is meant for debugging, as it is verbose.
+	Trace bool
+}
+
+func (config *ErrorInstrumentation) tracing() bool {
+	return config != nil && config.Trace && config.Logger != nil
+}
+
+func (config *ErrorInstrumentation) record(trace *ErrorTrace) {
+	if config == nil {
+		return
+	}
+
+	code := status.Code(trace.Result)
+
+	if config.Counter != nil {
+		config.Counter.inc(ErrorCounterKey{Case: trace.Matched, Code: code})
+	}
+
+	if config.tracing() {
+		config.Logger.Log(
+			quicklog.LevelInfo,
+			arpcmessages.NewErrorTrace(trace.Err, trace.Evaluated, trace.Matched, code),
+		)
+	}
+}
+
+func (errorHandler *errorHandlerImpl) Instrument(config *ErrorInstrumentation) ErrorHandler {
+	return errorHandler.with(func(output *errorHandlerImpl) {
+		output.instrument = config
+	})
+}
+
+func (errorHandler *errorHandlerImpl) Mappings() []arpcmessages.ErrorMapping {
+	output := make([]arpcmessages.ErrorMapping, 0, len(errorHandler.cases)+2)
+	for _, errorCase := range errorHandler.cases {
+		output = append(output, arpcmessages.ErrorMapping{
+			Case:  errorCase.name,
+			Match: errorCase.match,
+			Code:  errorCase.code,
+		})
+	}
+
+	passthroughCode := errorHandler.defaultCode.String()
+
+	switch errorHandler.statusPolicy.Mode {
+	case StatusKeep:
+		passthroughCode = "kept"
+	case StatusRemap:
+		passthroughCode = "remapped"
+	}
+
+	return append(
+		output,
+		arpcmessages.ErrorMapping{Case: passthroughCaseName, Match: "status", Code: passthroughCode},
+		arpcmessages.ErrorMapping{Case: defaultCaseName, Match: "any", Code: errorHandler.defaultCode.String()},
+	)
+}
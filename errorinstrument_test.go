@@ -0,0 +1,105 @@
+package arpc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+type instrumentedErr struct{}
+
+func (e *instrumentedErr) Error() string {
+	return "instrumented"
+}
+
+func TestErrorHandlerInstrumentation(t *testing.T) {
+	var (
+		errNoRows = errors.New("no rows")
+		errBook   = errors.New("book not found")
+	)
+
+	databaseErrors := arpc.NewErrorCaseGroup("database", arpc.CaseIs(errNoRows, codes.NotFound))
+
+	newHandler := func() arpc.ErrorHandler {
+		return arpc.HandleError(codes.Internal).
+			Is(errBook, codes.NotFound).Named("book").
+			As(new(*instrumentedErr), codes.InvalidArgument).
+			Include(databaseErrors)
+	}
+
+	t.Run("Explain", func(t *testing.T) {
+		trace := newHandler().Explain(errNoRows)
+
+		require.Equal(t, codes.NotFound, status.Code(trace.Result))
+		require.Equal(t, "database/no rows", trace.Matched)
+		require.Equal(t, []string{"book", "*arpc_test.instrumentedErr", "database/no rows"}, trace.Evaluated)
+
+		trace = newHandler().Explain(status.Error(codes.Unavailable, "upstream"))
+		require.Equal(t, "passthrough", trace.Matched)
+		require.Equal(t, codes.Internal, status.Code(trace.Result))
+
+		trace = newHandler().Explain(errors.New("uwups"))
+		require.Equal(t, "default", trace.Matched)
+		require.Equal(t, codes.Internal, status.Code(trace.Result))
+	})
+
+	t.Run("Counter", func(t *testing.T) {
+		counter := new(arpc.ErrorCounter)
+		handler := newHandler().Instrument(&arpc.ErrorInstrumentation{Counter: counter})
+
+		_ = handler.Handle(errBook)
+		_ = handler.Handle(errBook)
+		_ = handler.Handle(&instrumentedErr{})
+		_ = handler.Handle(errors.New("uwups"))
+
+		require.Equal(t, 2, counter.Count("book", codes.NotFound))
+		require.Equal(t, 0, counter.Count("book", codes.Internal))
+		require.Equal(t, map[arpc.ErrorCounterKey]int{
+			{Case: "book", Code: codes.NotFound}:                              2,
+			{Case: "*arpc_test.instrumentedErr", Code: codes.InvalidArgument}: 1,
+			{Case: "default", Code: codes.Internal}:                           1,
+		}, counter.Counts())
+	})
+
+	t.Run("Trace", func(t *testing.T) {
+		logger := quicklogmocks.NewMockLogger(t)
+		handler := newHandler().Instrument(&arpc.ErrorInstrumentation{Logger: logger, Trace: true})
+
+		logger.
+			On("Log", quicklog.LevelInfo, mock.MatchedBy(func(message quicklog.Message) bool {
+				json := message.RenderJSON()
+
+				return json["matched"] == "*arpc_test.instrumentedErr" &&
+					json["code"] == codes.InvalidArgument &&
+					len(json["evaluated"].([]string)) == 2
+			})).
+			Once()
+
+		require.Equal(t, codes.InvalidArgument, status.Code(handler.Handle(&instrumentedErr{})))
+	})
+
+	t.Run("Mappings", func(t *testing.T) {
+		handler := newHandler().
+			Test(func(_ error) (error, bool) { return nil, false }).
+			Passthrough(arpc.StatusPolicy{Mode: arpc.StatusKeep})
+
+		require.Equal(t, []arpcmessages.ErrorMapping{
+			{Case: "book", Match: "is book not found", Code: "NotFound"},
+			{Case: "*arpc_test.instrumentedErr", Match: "as *arpc_test.instrumentedErr", Code: "InvalidArgument"},
+			{Case: "database/no rows", Match: "is no rows", Code: "NotFound"},
+			{Case: "test", Match: "custom", Code: "dynamic"},
+			{Case: "passthrough", Match: "status", Code: "kept"},
+			{Case: "default", Match: "any", Code: "Internal"},
+		}, handler.Mappings())
+	})
+}
@@ -0,0 +1,77 @@
+package arpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorHandlerRule uses a specific handler for the methods matching any of the patterns. Patterns follow the syntax
+// of path.Match, so "/package.Service/*" overrides the handler of a whole service.
+type ErrorHandlerRule struct {
+	Methods []string
+	Handler ErrorHandler
+}
+
+// ErrorHandlerConfig configures the error handler interceptors.
+type ErrorHandlerConfig struct {
+	// Default handles the errors of methods that match no rule. When nil, those errors are returned as is.
+	Default ErrorHandler
+	// Rules override the default handler. Only the first rule matching a method is applied, so list per-method
+	// rules before per-service ones.
+	Rules []ErrorHandlerRule
+}
+
+func (config *ErrorHandlerConfig) handle(fullMethod string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Errors that are statuses themselves were mapped by the handler itself. Wrapped statuses, usually returned by
+	// calls to other services, go through the handler, so its status policy and sanitizer apply.
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok { //nolint:errorlint
+		return err
+	}
+
+	handler := config.Default
+
+	for _, rule := range config.Rules {
+		if matchAnyPattern(rule.Methods, fullMethod) {
+			handler = rule.Handler
+			break
+		}
+	}
+
+	if handler == nil {
+		return err
+	}
+
+	return handler.Handle(err)
+}
+
+// UnaryErrorHandlerInterceptor runs an ErrorHandler over the errors returned by unary handlers, so they don't have
+// to call Handle themselves. Errors that are statuses themselves, as returned by status.Error, are returned as is.
+//
+//	grpc.ChainUnaryInterceptor(arpc.UnaryErrorHandlerInterceptor(&arpc.ErrorHandlerConfig{
+//		Default: arpc.HandleError(codes.Internal).Is(ErrNotFound, codes.NotFound),
+//		Rules: []arpc.ErrorHandlerRule{
+//			{Methods: []string{"/library.Books/*"}, Handler: booksErrorHandler},
+//		},
+//	}))
+func UnaryErrorHandlerInterceptor(config *ErrorHandlerConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		return resp, config.handle(info.FullMethod, err)
+	}
+}
+
+// StreamErrorHandlerInterceptor runs an ErrorHandler over the errors returned by stream handlers.
+func StreamErrorHandlerInterceptor(config *ErrorHandlerConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return config.handle(info.FullMethod, handler(srv, ss))
+	}
+}
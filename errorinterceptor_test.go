@@ -0,0 +1,146 @@
+package arpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestErrorHandlerInterceptors(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	var unaryErr error
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			return nil, errNotFound
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return nil, unaryErr
+		},
+		FullDuplexCallF: func(_ testgrpc.TestService_FullDuplexCallServer) error {
+			return errNotFound
+		},
+	}
+
+	config := &arpc.ErrorHandlerConfig{
+		Default: arpc.HandleError(codes.Internal).Is(errNotFound, codes.NotFound),
+		Rules: []arpc.ErrorHandlerRule{
+			{
+				Methods: []string{"/grpc.testing.TestService/EmptyCall"},
+				Handler: arpc.HandleError(codes.Internal).Is(errNotFound, codes.FailedPrecondition),
+			},
+			{
+				Methods: []string{"/grpc.testing.TestService/*"},
+				Handler: arpc.HandleError(codes.Unknown),
+			},
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryErrorHandlerInterceptor(config)),
+		grpc.ChainStreamInterceptor(arpc.StreamErrorHandlerInterceptor(config)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("MethodRule", func(t *testing.T) {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+		require.Equal(t, "not found", status.Convert(err).Message())
+	})
+
+	t.Run("ServiceRule", func(t *testing.T) {
+		unaryErr = errNotFound
+
+		_, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.Equal(t, codes.Unknown, status.Code(err))
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		unaryErr = status.Error(codes.AlreadyExists, "uwups")
+
+		_, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+		require.Equal(t, "uwups", status.Convert(err).Message())
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		unaryErr = nil
+
+		_, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.NoError(t, err)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream, err := stub.Client.FullDuplexCall(ctx)
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Unknown, status.Code(err))
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		defaultConfig := &arpc.ErrorHandlerConfig{
+			Default: arpc.HandleError(codes.Internal).Is(errNotFound, codes.NotFound),
+		}
+
+		_, err := arpc.UnaryErrorHandlerInterceptor(defaultConfig)(
+			ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.testing.TestService/EmptyCall"},
+			func(_ context.Context, _ interface{}) (interface{}, error) { return nil, errNotFound },
+		)
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		// Without a default handler, errors are returned as is.
+		_, err = arpc.UnaryErrorHandlerInterceptor(new(arpc.ErrorHandlerConfig))(
+			ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.testing.TestService/EmptyCall"},
+			func(_ context.Context, _ interface{}) (interface{}, error) { return nil, errNotFound },
+		)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("WrappedStatus", func(t *testing.T) {
+		upstreamErr := status.Error(codes.Internal, "pq: connection to db-internal-42.local refused")
+
+		wrappedConfig := &arpc.ErrorHandlerConfig{
+			Default: arpc.HandleError(codes.Internal).
+				Passthrough(arpc.StatusPolicy{
+					Mode:  arpc.StatusRemap,
+					Remap: map[codes.Code]codes.Code{codes.NotFound: codes.FailedPrecondition},
+				}).
+				Sanitize(&arpc.SanitizeConfig{Release: true}),
+		}
+
+		call := func(err error) error {
+			_, err = arpc.UnaryErrorHandlerInterceptor(wrappedConfig)(
+				ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.testing.TestService/EmptyCall"},
+				func(_ context.Context, _ interface{}) (interface{}, error) { return nil, err },
+			)
+
+			return err
+		}
+
+		err := call(fmt.Errorf("get book: %w", status.Error(codes.NotFound, "book not found")))
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		err = call(fmt.Errorf("get book: %w", upstreamErr))
+		require.Equal(t, codes.Internal, status.Code(err))
+		require.NotContains(t, status.Convert(err).Message(), "db-internal-42")
+
+		// Statuses returned directly are considered mapped already.
+		require.Equal(t, upstreamErr, call(upstreamErr))
+	})
+}
@@ -0,0 +1,643 @@
+package arpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Default size limit of HTTP request bodies, matching the default limit of GRPC servers.
+const defaultGatewayMaxBodySize = 4 << 20
+
+// HTTPStatusFromCode returns the HTTP status code matching a GRPC code, following the mapping of
+// google/rpc/code.proto.
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// Client closed request, as used by Nginx.
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GatewayConfig configures a Gateway.
+type GatewayConfig struct {
+	// TLS secures the gateway with the given configuration. The TLS configuration of the GRPC server is not used
+	// by the gateway, which serves GRPC calls itself.
+	TLS *tls.Config
+
+	// MaxBodySize limits the size of HTTP request bodies. Defaults to 4MB.
+	MaxBodySize int64
+	// ShutdownTimeout is the time given to pending calls to complete when the gateway is closed. Defaults to 10
+	// seconds.
+	ShutdownTimeout time.Duration
+
+	// MarshalOptions and UnmarshalOptions customize the JSON encoding of messages.
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+
+	// GRPCWeb accepts GRPC-Web calls, in both binary and text modes, so browsers can call the server directly.
+	GRPCWeb bool
+	// CORS lets browsers call the gateway from other origins. It applies to GRPC-Web and HTTP/JSON calls.
+	CORS *CORSConfig
+
+	// Admin mounts the admin endpoint on the gateway, under AdminConfig.Prefix. The endpoint is then reachable by
+	// anyone who can reach the gateway, so make sure access to the service is restricted, for example with Cloud Run
+	// IAM. Port, Host and Listener are ignored.
+	Admin *AdminConfig
+
+	// Catalog overrides the HTTP status code of errors built from its entries, with CatalogEntry.HTTPCode.
+	Catalog *ErrorCatalog
+}
+
+// gatewayMethod describes a method registered on the GRPC server.
+type gatewayMethod struct {
+	fullMethod string
+	input      protoreflect.MessageType
+	output     protoreflect.MessageType
+
+	clientStream bool
+	serverStream bool
+}
+
+// Gateway serves native GRPC calls and HTTP/JSON calls on the same listener. This is useful on platforms that
+// expose a single port, such as Cloud Run.
+//
+// HTTP/JSON calls use the same path as GRPC methods, and the JSON encoding of their request message as body:
+//
+//	curl -X POST http://localhost:8080/package.Service/Method -d '{"name": "foo"}'
+//
+// Status errors are converted to the matching HTTP status code, and a JSON representation of the google.rpc.Status.
+// Calls go through the interceptors of the GRPC server, exactly like native calls. Only unary methods can be called
+// over HTTP/JSON.
+//
+// Methods annotated with google.api.http are also exposed as REST routes, following the path templates, body and
+// response body mappings of their annotations. Annotations are read at runtime, from the registered file descriptors.
+// Status errors, such as the ones produced by an ErrorHandler, are converted the same way for both.
+//
+// GRPC-Web calls can be enabled with GatewayConfig.GRPCWeb, and configured for browsers with GatewayConfig.CORS.
+//
+// Native GRPC calls are served using the http.Handler implementation of the GRPC server, which does not support
+// every server option (for example, keepalive settings are ignored).
+type Gateway struct {
+	server *grpc.Server
+	config *GatewayConfig
+
+	httpServer *http.Server
+	admin      http.Handler
+
+	methods map[string]*gatewayMethod
+	routes  []*restRoute
+
+	// Native GRPC calls in flight, and whether the gateway is closing. Calls served over h2c run on hijacked
+	// connections, that the HTTP server does not wait for when shutting down.
+	grpcCalls atomic.Int64
+	closing   atomic.Bool
+}
+
+// NewGateway creates a gateway for the given server. Services must be registered on the server before the gateway
+// is created. It returns an error if the google.api.http annotation of a method is invalid.
+//
+//	listener, server, err := arpc.StartServer(port)
+//	pb.RegisterMyServiceServer(server, myService)
+//
+//	gateway, err := arpc.NewGateway(server, nil)
+//	defer gateway.Close()
+//
+//	err = gateway.Serve(listener)
+func NewGateway(server *grpc.Server, config *GatewayConfig) (*Gateway, error) {
+	if config == nil {
+		config = new(GatewayConfig)
+	}
+
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultGatewayMaxBodySize
+	}
+
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 10 * time.Second
+	}
+
+	gateway := &Gateway{server: server, config: config}
+
+	if err := gateway.loadMethods(); err != nil {
+		return nil, fmt.Errorf("create gateway: %w", err)
+	}
+
+	if config.Admin != nil {
+		if config.Admin.Prefix == "" {
+			config.Admin.Prefix = "/admin"
+		}
+
+		gateway.admin = NewAdminHandler(config.Admin)
+	}
+
+	gateway.httpServer = &http.Server{
+		// Serve HTTP/2 without TLS, as GRPC clients do.
+		Handler:           h2c.NewHandler(gateway, &http2.Server{}),
+		TLSConfig:         config.TLS,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return gateway, nil
+}
+
+// Serve accepts connections on the listener, until the gateway is closed.
+func (gateway *Gateway) Serve(listener net.Listener) error {
+	var err error
+
+	if gateway.config.TLS != nil {
+		// Certificates are already loaded in the TLS configuration.
+		err = gateway.httpServer.ServeTLS(listener, "", "")
+	} else {
+		err = gateway.httpServer.Serve(listener)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return fmt.Errorf("serve gateway: %w", err)
+}
+
+// Close waits for pending calls to complete, then stops the gateway and the GRPC server. Use it instead of
+// CloseServer. Calls still pending after GatewayConfig.ShutdownTimeout are interrupted, and native GRPC calls
+// received in the meantime are rejected with codes.Unavailable.
+func (gateway *Gateway) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), gateway.config.ShutdownTimeout)
+	defer cancel()
+
+	gateway.closing.Store(true)
+
+	err := gateway.httpServer.Shutdown(ctx)
+	if err != nil {
+		err = gateway.httpServer.Close()
+	}
+
+	// The http.Handler implementation of GRPC servers does not support graceful stops, so wait for native calls
+	// before stopping it.
+	if waitErr := gateway.waitGRPCCalls(ctx); err == nil {
+		err = waitErr
+	}
+
+	gateway.server.Stop()
+
+	if err != nil {
+		return fmt.Errorf("close gateway: %w", err)
+	}
+
+	return nil
+}
+
+func (gateway *Gateway) waitGRPCCalls(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for gateway.grpcCalls.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// Pass a native GRPC call to the GRPC server, unless the gateway is closing.
+func (gateway *Gateway) serveGRPC(w http.ResponseWriter, r *http.Request) {
+	// Count the call before checking the state of the gateway, so Close cannot miss it.
+	gateway.grpcCalls.Add(1)
+	defer gateway.grpcCalls.Add(-1)
+
+	if gateway.closing.Load() {
+		// Trailers-only response.
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unavailable)))
+		w.Header().Set("Grpc-Message", "gateway is closing")
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	gateway.server.ServeHTTP(w, r)
+}
+
+func isGRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+
+	return r.ProtoMajor == 2 &&
+		(contentType == "application/grpc" ||
+			strings.HasPrefix(contentType, "application/grpc+") ||
+			strings.HasPrefix(contentType, "application/grpc;"))
+}
+
+func isJSONRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && mediaType == "application/json"
+}
+
+// ServeHTTP routes native GRPC calls to the GRPC server, and serves GRPC-Web and HTTP/JSON calls.
+func (gateway *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isGRPCRequest(r) {
+		gateway.serveGRPC(w, r)
+		return
+	}
+
+	if gateway.admin != nil && isAdminPath(gateway.config.Admin.Prefix, r.URL.Path) {
+		gateway.admin.ServeHTTP(w, r)
+		return
+	}
+
+	if gateway.config.CORS != nil && !gateway.config.CORS.apply(w, r) {
+		return
+	}
+
+	if gateway.config.GRPCWeb && isGRPCWebRequest(r) {
+		gateway.serveGRPCWeb(w, r)
+		return
+	}
+
+	if route, values, ok := gateway.route(r); ok {
+		gateway.serveREST(w, r, route, values)
+		return
+	}
+
+	method, ok := gateway.method(r.URL.Path)
+	if !ok || r.Method != http.MethodPost || !isJSONRequest(r) {
+		gateway.writeError(w, status.Newf(codes.NotFound, "no route for %s %s", r.Method, r.URL.Path))
+		return
+	}
+
+	gateway.serveJSON(w, r, method)
+}
+
+// Collect the methods registered on the GRPC server, along with their message types and REST routes.
+func (gateway *Gateway) loadMethods() error {
+	gateway.methods = make(map[string]*gatewayMethod)
+
+	for serviceName, info := range gateway.server.GetServiceInfo() {
+		descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+		if err != nil {
+			continue
+		}
+
+		service, ok := descriptor.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+
+		for _, methodInfo := range info.Methods {
+			methodDescriptor := service.Methods().ByName(protoreflect.Name(methodInfo.Name))
+			if methodDescriptor == nil {
+				continue
+			}
+
+			fullMethod := "/" + serviceName + "/" + methodInfo.Name
+			method := &gatewayMethod{
+				fullMethod:   fullMethod,
+				input:        messageType(methodDescriptor.Input()),
+				output:       messageType(methodDescriptor.Output()),
+				clientStream: methodInfo.IsClientStream,
+				serverStream: methodInfo.IsServerStream,
+			}
+
+			gateway.methods[fullMethod] = method
+
+			if err := gateway.loadRoutes(method, methodDescriptor); err != nil {
+				return err
+			}
+		}
+	}
+
+	gateway.sortRoutes()
+
+	return nil
+}
+
+func (gateway *Gateway) method(fullMethod string) (*gatewayMethod, bool) {
+	method, ok := gateway.methods[fullMethod]
+
+	return method, ok
+}
+
+// Prefer generated types, so messages go through the interceptors with their concrete type.
+func messageType(descriptor protoreflect.MessageDescriptor) protoreflect.MessageType {
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(descriptor.FullName())
+	if err != nil {
+		return dynamicpb.NewMessageType(descriptor)
+	}
+
+	return messageType
+}
+
+func (gateway *Gateway) serveJSON(w http.ResponseWriter, r *http.Request, method *gatewayMethod) {
+	if method.clientStream || method.serverStream {
+		gateway.writeError(w, status.Newf(
+			codes.Unimplemented, "streaming method %s cannot be called over HTTP/JSON", method.fullMethod,
+		))
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, gateway.config.MaxBodySize))
+	if err != nil {
+		gateway.writeError(w, status.Newf(codes.InvalidArgument, "read request body: %v", err))
+		return
+	}
+
+	in := method.input.New().Interface()
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err = gateway.config.UnmarshalOptions.Unmarshal(body, in); err != nil {
+			gateway.writeError(w, status.Newf(codes.InvalidArgument, "decode request body: %v", err))
+			return
+		}
+	}
+
+	out := method.output.New().Interface()
+
+	header, st := gateway.invoke(r, method.fullMethod, in, out)
+	copyGatewayHeader(w.Header(), header)
+
+	if st.Code() != codes.OK {
+		gateway.writeError(w, st)
+		return
+	}
+
+	gateway.writeJSON(w, http.StatusOK, out)
+}
+
+func (gateway *Gateway) writeJSON(w http.ResponseWriter, code int, message proto.Message) {
+	data, err := gateway.config.MarshalOptions.Marshal(message)
+	if err != nil {
+		gateway.writeError(w, status.Newf(codes.Internal, "encode response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+// writeError writes a status error as a JSON google.rpc.Status, with the matching HTTP status code.
+func (gateway *Gateway) writeError(w http.ResponseWriter, st *status.Status) {
+	data, err := gateway.config.MarshalOptions.Marshal(st.Proto())
+	if err != nil {
+		// Details whose type is unknown cannot be encoded to JSON.
+		data, _ = gateway.config.MarshalOptions.Marshal(&spb.Status{Code: int32(st.Code()), Message: st.Message()})
+	}
+
+	httpCode, ok := gateway.config.Catalog.httpCode(st)
+	if !ok {
+		httpCode = HTTPStatusFromCode(st.Code())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	_, _ = w.Write(data)
+}
+
+// Headers that are specific to the GRPC protocol, and must not leak into HTTP responses.
+var gatewayReservedHeaders = map[string]bool{
+	"Content-Type":            true,
+	"Content-Length":          true,
+	"Trailer":                 true,
+	"Date":                    true,
+	"Grpc-Status":             true,
+	"Grpc-Message":            true,
+	"Grpc-Status-Details-Bin": true,
+	"Grpc-Encoding":           true,
+	"Grpc-Accept-Encoding":    true,
+}
+
+func copyGatewayHeader(dst, src http.Header) {
+	for key, values := range src {
+		if gatewayReservedHeaders[key] {
+			continue
+		}
+
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+// Headers of the HTTP request that do not make sense in the GRPC request.
+var gatewayHopHeaders = []string{
+	"Accept", "Accept-Encoding", "Connection", "Content-Length", "Content-Type", "Keep-Alive", "Te",
+	"Transfer-Encoding", "Upgrade",
+}
+
+// invoke calls a unary method of the GRPC server, through its http.Handler implementation. Calls made this way go
+// through the interceptors of the server, and keep the peer information of the HTTP request. The incoming HTTP
+// headers are passed as metadata, and the metadata sent back by the server is returned as HTTP headers.
+func (gateway *Gateway) invoke(
+	r *http.Request, fullMethod string, in, out proto.Message,
+) (http.Header, *status.Status) {
+	data, err := proto.Marshal(in)
+	if err != nil {
+		return nil, status.Newf(codes.Internal, "encode request: %v", err)
+	}
+
+	frame := make([]byte, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data))) //nolint:gosec
+	copy(frame[5:], data)
+
+	req := r.Clone(r.Context())
+	req.Method = http.MethodPost
+	req.URL = &url.URL{Path: fullMethod}
+	req.RequestURI = fullMethod
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.Body = io.NopCloser(bytes.NewReader(frame))
+	req.ContentLength = int64(len(frame))
+
+	for _, key := range gatewayHopHeaders {
+		req.Header.Del(key)
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+
+	recorder := newGatewayRecorder()
+	gateway.server.ServeHTTP(recorder, req)
+
+	header, trailer := recorder.result()
+	if recorder.code != http.StatusOK {
+		return header, status.Newf(codes.Internal, "call %s: %s", fullMethod, strings.TrimSpace(recorder.body.String()))
+	}
+
+	st := statusFromTrailer(trailer)
+	copyGatewayHeader(header, trailer)
+
+	if st.Code() != codes.OK {
+		return header, st
+	}
+
+	message, err := readGatewayFrame(&recorder.body)
+	if err != nil {
+		return header, status.Newf(codes.Internal, "read response: %v", err)
+	}
+
+	if err = proto.Unmarshal(message, out); err != nil {
+		return header, status.Newf(codes.Internal, "decode response: %v", err)
+	}
+
+	return header, st
+}
+
+func readGatewayFrame(r io.Reader) ([]byte, error) {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, fmt.Errorf("read frame prefix: %w", err)
+	}
+
+	if prefix[0] != 0 {
+		return nil, errors.New("compressed messages are not supported")
+	}
+
+	message := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, fmt.Errorf("read frame: %w", err)
+	}
+
+	return message, nil
+}
+
+// statusFromTrailer reads a status from the trailer of a GRPC response.
+func statusFromTrailer(trailer http.Header) *status.Status {
+	rawCode := trailer.Get("Grpc-Status")
+	if rawCode == "" {
+		return status.New(codes.Unknown, "missing status in response")
+	}
+
+	code, err := strconv.Atoi(rawCode)
+	if err != nil {
+		return status.Newf(codes.Unknown, "invalid status in response: %q", rawCode)
+	}
+
+	message, err := url.PathUnescape(trailer.Get("Grpc-Message"))
+	if err != nil {
+		message = trailer.Get("Grpc-Message")
+	}
+
+	if rawDetails := trailer.Get("Grpc-Status-Details-Bin"); rawDetails != "" {
+		details, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(rawDetails, "="))
+		if err == nil {
+			st := new(spb.Status)
+			if err = proto.Unmarshal(details, st); err == nil {
+				return status.FromProto(st)
+			}
+		}
+	}
+
+	return status.New(codes.Code(code), message) //nolint:gosec
+}
+
+// gatewayRecorder captures the response of a GRPC server, for a single unary call.
+type gatewayRecorder struct {
+	header http.Header
+	// Copy of the header, when it was sent. Values set afterward are trailers.
+	sent http.Header
+	code int
+	body bytes.Buffer
+}
+
+func newGatewayRecorder() *gatewayRecorder {
+	return &gatewayRecorder{header: make(http.Header)}
+}
+
+func (recorder *gatewayRecorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *gatewayRecorder) WriteHeader(code int) {
+	if recorder.sent != nil {
+		return
+	}
+
+	recorder.code = code
+	recorder.sent = recorder.header.Clone()
+}
+
+func (recorder *gatewayRecorder) Write(data []byte) (int, error) {
+	recorder.WriteHeader(http.StatusOK)
+	return recorder.body.Write(data) //nolint:wrapcheck
+}
+
+func (recorder *gatewayRecorder) Flush() {
+	recorder.WriteHeader(http.StatusOK)
+}
+
+func (recorder *gatewayRecorder) result() (http.Header, http.Header) {
+	recorder.WriteHeader(http.StatusOK)
+	return recorder.sent, splitTrailer(recorder.header, recorder.sent)
+}
+
+// splitTrailer returns the trailer of a GRPC response, from the final state of its header. Trailers are either
+// declared with http.TrailerPrefix, or set after the header was sent.
+func splitTrailer(header, sent http.Header) http.Header {
+	trailer := make(http.Header)
+
+	for key, values := range header {
+		switch {
+		case strings.HasPrefix(key, http.TrailerPrefix):
+			trailer[http.CanonicalHeaderKey(strings.TrimPrefix(key, http.TrailerPrefix))] = values
+		case sent.Get(key) == "":
+			trailer[key] = values
+		}
+	}
+
+	return trailer
+}
@@ -0,0 +1,219 @@
+package arpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func startGateway(
+	t *testing.T, stub *arpcmocks.StubServer, config *arpc.GatewayConfig, options ...grpc.ServerOption,
+) string {
+	t.Helper()
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{
+		Ephemeral: true,
+		Host:      "127.0.0.1",
+		Options:   options,
+	})
+	require.NoError(t, err)
+
+	testgrpc.RegisterTestServiceServer(server, stub)
+
+	gateway, err := arpc.NewGateway(server, config)
+	require.NoError(t, err)
+
+	go func() {
+		_ = gateway.Serve(listener)
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, gateway.Close())
+	})
+
+	return listener.Addr().String()
+}
+
+func TestGateway(t *testing.T) {
+	stub := &arpcmocks.StubServer{
+		UnaryCallF: func(ctx context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			if in.GetResponseSize() < 0 {
+				return nil, status.Error(codes.InvalidArgument, "negative response size")
+			}
+
+			md, _ := metadata.FromIncomingContext(ctx)
+			_ = grpc.SetHeader(ctx, metadata.Pairs("x-user", strings.Join(md.Get("x-user"), ",")))
+
+			return &testgrpc.SimpleResponse{Username: "user"}, nil
+		},
+		FullDuplexCallF: func(_ testgrpc.TestService_FullDuplexCallServer) error {
+			return nil
+		},
+	}
+
+	addr := startGateway(t, stub, nil, grpc.ChainUnaryInterceptor(arpc.UnaryRequestIDInterceptor("")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	post := func(t *testing.T, path, contentType, body string) (*http.Response, map[string]interface{}) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+addr+path, strings.NewReader(body))
+		require.NoError(t, err)
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		req.Header.Set("X-User", "alice")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer res.Body.Close()
+
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var output map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &output), string(data))
+
+		return res, output
+	}
+
+	t.Run("GRPC", func(t *testing.T) {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+
+		defer conn.Close()
+
+		resp, err := testgrpc.NewTestServiceClient(conn).UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.NoError(t, err)
+		require.Equal(t, "user", resp.GetUsername())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		res, output := post(t, "/grpc.testing.TestService/UnaryCall", "application/json", `{"responseSize": 3}`)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+		require.Equal(t, "alice", res.Header.Get("X-User"))
+		require.Len(t, res.Header.Get(arpc.DefaultRequestIDHeader), 32)
+		require.Equal(t, map[string]interface{}{"username": "user"}, output)
+	})
+
+	t.Run("EmptyBody", func(t *testing.T) {
+		res, output := post(t, "/grpc.testing.TestService/UnaryCall", "", "")
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "user", output["username"])
+	})
+
+	t.Run("StatusError", func(t *testing.T) {
+		res, output := post(t, "/grpc.testing.TestService/UnaryCall", "application/json", `{"responseSize": -1}`)
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Equal(t, map[string]interface{}{
+			"code":    float64(codes.InvalidArgument),
+			"message": "negative response size",
+		}, output)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		res, output := post(t, "/grpc.testing.TestService/UnaryCall", "application/json", `{"unknown": true}`)
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Equal(t, float64(codes.InvalidArgument), output["code"])
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		res, output := post(t, "/grpc.testing.TestService/FullDuplexCall", "application/json", `{}`)
+
+		require.Equal(t, http.StatusNotImplemented, res.StatusCode)
+		require.Equal(t, float64(codes.Unimplemented), output["code"])
+	})
+
+	t.Run("UnknownMethod", func(t *testing.T) {
+		res, output := post(t, "/grpc.testing.TestService/Unknown", "application/json", `{}`)
+
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.Equal(t, float64(codes.NotFound), output["code"])
+	})
+}
+
+func TestGatewayClose(t *testing.T) {
+	started := make(chan struct{})
+
+	stub := &arpcmocks.StubServer{
+		UnaryCallF: func(_ context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			if in.GetResponseSize() > 0 {
+				close(started)
+				time.Sleep(200 * time.Millisecond)
+			}
+
+			return &testgrpc.SimpleResponse{Username: "user"}, nil
+		},
+	}
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
+	require.NoError(t, err)
+
+	testgrpc.RegisterTestServiceServer(server, stub)
+
+	gateway, err := arpc.NewGateway(server, nil)
+	require.NoError(t, err)
+
+	go func() {
+		_ = gateway.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	client := testgrpc.NewTestServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	slowErr := make(chan error, 1)
+
+	go func() {
+		_, callErr := client.UnaryCall(ctx, &testgrpc.SimpleRequest{ResponseSize: 1})
+		slowErr <- callErr
+	}()
+	<-started
+
+	closeErr := make(chan error, 1)
+
+	go func() {
+		closeErr <- gateway.Close()
+	}()
+
+	// New calls are rejected while the gateway is closing.
+	require.Eventually(t, func() bool {
+		_, callErr := client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		return status.Code(callErr) == codes.Unavailable
+	}, time.Second, 10*time.Millisecond)
+
+	// Pending calls complete before the GRPC server is stopped.
+	require.NoError(t, <-slowErr)
+	require.NoError(t, <-closeErr)
+}
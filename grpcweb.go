@@ -0,0 +1,256 @@
+package arpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ErrInvalidGRPCWebText = errors.New("invalid grpc-web-text body")
+
+// Flag of GRPC-Web frames that carry trailers, instead of a message.
+const grpcWebTrailerFlag = 0x80
+
+// Headers GRPC-Web clients send, and browsers must be allowed to send.
+var defaultCORSAllowedHeaders = []string{
+	"Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout", "Authorization",
+}
+
+// Headers GRPC-Web clients read, and browsers must be allowed to expose.
+var defaultCORSExposedHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+
+// CORSConfig configures the cross-origin requests allowed by a Gateway.
+type CORSConfig struct {
+	// AllowedOrigins lists origin patterns, following the syntax of path.Match, for example
+	// "https://*.example.com". A single "*" allows every origin.
+	AllowedOrigins []string
+	// AllowedHeaders are allowed in requests, in addition to the headers used by GRPC-Web clients.
+	AllowedHeaders []string
+	// ExposedHeaders can be read by browsers, in addition to the headers used by GRPC-Web clients.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization headers. It is ignored if AllowedOrigins
+	// contains "*", as it would let any website call the gateway on behalf of its users.
+	AllowCredentials bool
+	// MaxAge is the time browsers may cache the result of a preflight request.
+	MaxAge time.Duration
+}
+
+// apply sets the CORS headers of a response. It returns false if the request was a preflight request, in which
+// case the response was already written.
+func (config *CORSConfig) apply(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	w.Header().Add("Vary", "Origin")
+
+	if !matchAnyPattern(config.AllowedOrigins, origin) {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+			return false
+		}
+
+		// Browsers will not expose the response to the page.
+		return true
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+
+	if config.AllowCredentials && !slices.Contains(config.AllowedOrigins, "*") {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		w.Header().Set(
+			"Access-Control-Expose-Headers",
+			strings.Join(slices.Concat(defaultCORSExposedHeaders, config.ExposedHeaders), ", "),
+		)
+
+		return true
+	}
+
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+	w.Header().Set(
+		"Access-Control-Allow-Headers",
+		strings.Join(slices.Concat(defaultCORSAllowedHeaders, config.AllowedHeaders), ", "),
+	)
+
+	if config.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return false
+}
+
+func isGRPCWebRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web")
+}
+
+// decodeGRPCWebText decodes the body of a grpc-web-text request. Clients may send multiple base64 chunks, each with
+// its own padding, so the body is decoded one quantum at a time.
+func decodeGRPCWebText(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	if len(data)%4 != 0 {
+		return nil, ErrInvalidGRPCWebText
+	}
+
+	output := make([]byte, 0, base64.StdEncoding.DecodedLen(len(data)))
+	quantum := make([]byte, 3)
+
+	for i := 0; i < len(data); i += 4 {
+		n, err := base64.StdEncoding.Decode(quantum, data[i:i+4])
+		if err != nil {
+			return nil, errors.Join(ErrInvalidGRPCWebText, err)
+		}
+
+		output = append(output, quantum[:n]...)
+	}
+
+	return output, nil
+}
+
+// serveGRPCWeb translates a GRPC-Web call into a native GRPC call, served by the http.Handler implementation of
+// the GRPC server. Responses are streamed back to the client as they are produced.
+func (gateway *Gateway) serveGRPCWeb(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, "application/grpc-web-text")
+
+	// Subtype of the message encoding, such as "+proto".
+	subtype := strings.TrimPrefix(strings.TrimPrefix(contentType, "application/grpc-web"), "-text")
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, gateway.config.MaxBodySize))
+	if err != nil {
+		gateway.writeError(w, status.Newf(codes.InvalidArgument, "read request body: %v", err))
+		return
+	}
+
+	if text {
+		if body, err = decodeGRPCWebText(body); err != nil {
+			gateway.writeError(w, status.New(codes.InvalidArgument, err.Error()))
+			return
+		}
+	}
+
+	req := r.Clone(r.Context())
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	for _, key := range gatewayHopHeaders {
+		req.Header.Del(key)
+	}
+
+	req.Header.Set("Content-Type", "application/grpc"+subtype)
+
+	writer := &grpcWebWriter{w: w, header: make(http.Header), contentType: contentType, text: text}
+	gateway.server.ServeHTTP(writer, req)
+	writer.finish()
+}
+
+// grpcWebWriter converts the response of a GRPC server to GRPC-Web. GRPC-Web clients cannot read HTTP trailers, so
+// they are sent as a final frame in the body instead.
+type grpcWebWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+	// Copy of the header, when it was sent. Values set afterward are trailers.
+	sent http.Header
+	code int
+
+	contentType string
+	text        bool
+}
+
+func (writer *grpcWebWriter) Header() http.Header {
+	return writer.header
+}
+
+func (writer *grpcWebWriter) WriteHeader(code int) {
+	if writer.sent != nil {
+		return
+	}
+
+	writer.code = code
+	writer.sent = writer.header.Clone()
+
+	copyGatewayHeader(writer.w.Header(), writer.header)
+
+	if code == http.StatusOK {
+		writer.w.Header().Set("Content-Type", writer.contentType)
+	} else {
+		// Errors of the GRPC server at the HTTP level, written with http.Error.
+		writer.w.Header().Set("Content-Type", writer.header.Get("Content-Type"))
+	}
+
+	writer.w.WriteHeader(code)
+}
+
+func (writer *grpcWebWriter) Write(data []byte) (int, error) {
+	writer.WriteHeader(http.StatusOK)
+
+	if !writer.text || writer.code != http.StatusOK {
+		return writer.w.Write(data) //nolint:wrapcheck
+	}
+
+	if _, err := writer.w.Write([]byte(base64.StdEncoding.EncodeToString(data))); err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+
+	return len(data), nil
+}
+
+func (writer *grpcWebWriter) Flush() {
+	writer.WriteHeader(http.StatusOK)
+
+	if flusher, ok := writer.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish writes the trailer frame, once the GRPC server is done with the call.
+func (writer *grpcWebWriter) finish() {
+	writer.WriteHeader(http.StatusOK)
+
+	if writer.code != http.StatusOK {
+		return
+	}
+
+	trailer := splitTrailer(writer.header, writer.sent)
+
+	keys := make([]string, 0, len(trailer))
+	for key := range trailer {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	var payload bytes.Buffer
+
+	for _, key := range keys {
+		for _, value := range trailer[key] {
+			payload.WriteString(strings.ToLower(key) + ": " + value + "\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+payload.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(payload.Len())) //nolint:gosec
+	frame = append(frame, payload.Bytes()...)
+
+	_, _ = writer.Write(frame)
+	writer.Flush()
+}
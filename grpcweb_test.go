@@ -0,0 +1,318 @@
+package arpc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+type grpcWebResponse struct {
+	header   http.Header
+	messages [][]byte
+	trailer  map[string]string
+}
+
+func grpcWebFrame(t *testing.T, message proto.Message) []byte {
+	t.Helper()
+
+	data, err := proto.Marshal(message)
+	require.NoError(t, err)
+
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+
+	return append(frame, data...)
+}
+
+func grpcWebCall(
+	t *testing.T, client *http.Client, url string, text bool, message proto.Message,
+) *grpcWebResponse {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	body := grpcWebFrame(t, message)
+	contentType := "application/grpc-web+proto"
+
+	if text {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+		contentType = "application/grpc-web-text+proto"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Grpc-Web", "1")
+	req.Header.Set("Origin", "https://app.example.com")
+
+	res, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, contentType, res.Header.Get("Content-Type"))
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	if text {
+		// Each write of the server is encoded separately, with its own padding.
+		var decoded []byte
+
+		for i := 0; i < len(data); i += 4 {
+			quantum, err := base64.StdEncoding.DecodeString(string(data[i : i+4]))
+			require.NoError(t, err)
+
+			decoded = append(decoded, quantum...)
+		}
+
+		data = decoded
+	}
+
+	output := &grpcWebResponse{header: res.Header, trailer: make(map[string]string)}
+
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 5)
+
+		flag, size := data[0], binary.BigEndian.Uint32(data[1:5])
+		payload := data[5 : 5+size]
+		data = data[5+size:]
+
+		if flag&0x80 == 0 {
+			output.messages = append(output.messages, payload)
+			continue
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(string(payload)), "\r\n") {
+			key, value, _ := strings.Cut(line, ": ")
+			output.trailer[key] = value
+		}
+	}
+
+	return output
+}
+
+func TestGRPCWeb(t *testing.T) {
+	stub := &arpcmocks.StubServer{
+		UnaryCallF: func(_ context.Context, in *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			if in.GetResponseSize() < 0 {
+				return nil, status.Error(codes.InvalidArgument, "negative response size")
+			}
+
+			return &testgrpc.SimpleResponse{Username: "user"}, nil
+		},
+		FullDuplexCallF: func(stream testgrpc.TestService_FullDuplexCallServer) error {
+			in, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+
+			for _, param := range in.GetResponseParameters() {
+				err = stream.Send(&testgrpc.StreamingOutputCallResponse{
+					Payload: &testgrpc.Payload{Body: make([]byte, param.GetSize())},
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	addr := startGateway(t, stub, &arpc.GatewayConfig{
+		GRPCWeb: true,
+		CORS: &arpc.CORSConfig{
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedHeaders: []string{"X-Custom"},
+			MaxAge:         time.Hour,
+		},
+	}, grpc.ChainUnaryInterceptor(arpc.UnaryRequestIDInterceptor("")))
+
+	clients := map[string]*http.Client{
+		"HTTP1": http.DefaultClient,
+		"HTTP2": {
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					return new(net.Dialer).DialContext(ctx, network, addr)
+				},
+			},
+		},
+	}
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			for _, text := range []bool{false, true} {
+				mode := "Binary"
+				if text {
+					mode = "Text"
+				}
+
+				t.Run(mode, func(t *testing.T) {
+					t.Run("Unary", func(t *testing.T) {
+						res := grpcWebCall(
+							t, client, "http://"+addr+"/grpc.testing.TestService/UnaryCall", text,
+							new(testgrpc.SimpleRequest),
+						)
+
+						require.Len(t, res.messages, 1)
+
+						out := new(testgrpc.SimpleResponse)
+						require.NoError(t, proto.Unmarshal(res.messages[0], out))
+						require.Equal(t, "user", out.GetUsername())
+
+						require.Equal(t, "0", res.trailer["grpc-status"])
+						require.Len(t, res.header.Get(arpc.DefaultRequestIDHeader), 32)
+						require.Equal(t, "https://app.example.com", res.header.Get("Access-Control-Allow-Origin"))
+						require.Contains(t, res.header.Get("Access-Control-Expose-Headers"), "Grpc-Status")
+					})
+
+					t.Run("Error", func(t *testing.T) {
+						res := grpcWebCall(
+							t, client, "http://"+addr+"/grpc.testing.TestService/UnaryCall", text,
+							&testgrpc.SimpleRequest{ResponseSize: -1},
+						)
+
+						require.Empty(t, res.messages)
+						require.Equal(t, "3", res.trailer["grpc-status"])
+						require.Equal(t, "negative response size", res.trailer["grpc-message"])
+					})
+
+					t.Run("ServerStream", func(t *testing.T) {
+						res := grpcWebCall(
+							t, client, "http://"+addr+"/grpc.testing.TestService/FullDuplexCall", text,
+							&testgrpc.StreamingOutputCallRequest{
+								ResponseParameters: []*testgrpc.ResponseParameters{{Size: 1}, {Size: 2}, {Size: 3}},
+							},
+						)
+
+						require.Len(t, res.messages, 3)
+
+						for i, message := range res.messages {
+							out := new(testgrpc.StreamingOutputCallResponse)
+							require.NoError(t, proto.Unmarshal(message, out))
+							require.Len(t, out.GetPayload().GetBody(), i+1)
+						}
+
+						require.Equal(t, "0", res.trailer["grpc-status"])
+					})
+				})
+			}
+		})
+	}
+
+	t.Run("Preflight", func(t *testing.T) {
+		preflight := func(t *testing.T, origin string) *http.Response {
+			t.Helper()
+
+			req, err := http.NewRequestWithContext(
+				context.Background(), http.MethodOptions, "http://"+addr+"/grpc.testing.TestService/UnaryCall", nil,
+			)
+			require.NoError(t, err)
+
+			req.Header.Set("Origin", origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			return res
+		}
+
+		t.Run("Allowed", func(t *testing.T) {
+			res := preflight(t, "https://app.example.com")
+
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+			require.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+			require.Contains(t, res.Header.Get("Access-Control-Allow-Methods"), http.MethodPost)
+			require.Contains(t, res.Header.Get("Access-Control-Allow-Headers"), "X-Grpc-Web")
+			require.Contains(t, res.Header.Get("Access-Control-Allow-Headers"), "X-Custom")
+			require.Equal(t, "3600", res.Header.Get("Access-Control-Max-Age"))
+		})
+
+		t.Run("Forbidden", func(t *testing.T) {
+			res := preflight(t, "https://evil.com")
+
+			require.Equal(t, http.StatusForbidden, res.StatusCode)
+			require.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+		})
+	})
+}
+
+func TestCORSCredentials(t *testing.T) {
+	stub := &arpcmocks.StubServer{
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	}
+
+	testCases := []struct {
+		name string
+
+		allowedOrigins []string
+
+		expectCredentials string
+	}{
+		{
+			name: "Origin",
+
+			allowedOrigins: []string{"https://app.example.com"},
+
+			expectCredentials: "true",
+		},
+		{
+			name: "Wildcard",
+
+			allowedOrigins: []string{"https://app.example.com", "*"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			addr := startGateway(t, stub, &arpc.GatewayConfig{
+				CORS: &arpc.CORSConfig{AllowedOrigins: testCase.allowedOrigins, AllowCredentials: true},
+			})
+
+			req, err := http.NewRequestWithContext(
+				context.Background(), http.MethodOptions, "http://"+addr+"/grpc.testing.TestService/UnaryCall", nil,
+			)
+			require.NoError(t, err)
+
+			req.Header.Set("Origin", "https://app.example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+			require.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+			require.Equal(t, testCase.expectCredentials, res.Header.Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
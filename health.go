@@ -117,3 +117,50 @@ func NewHealthServer(depsCheck *DepsCheck, watchInterval time.Duration) healthpb
 		watchInterval: watchInterval,
 	}
 }
+
+// DependencyHealth is the status of a single dependency.
+type DependencyHealth struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthSnapshot is the status of every dependency and service, at a given time.
+type HealthSnapshot struct {
+	// Status is the generic health of the server, as reported for the empty service name.
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyHealth `json:"dependencies"`
+	Services     map[string]string           `json:"services"`
+}
+
+// Snapshot checks every dependency once, and returns the resulting status of each service.
+func (depsCheck *DepsCheck) Snapshot() *HealthSnapshot {
+	snapshot := &HealthSnapshot{
+		Status:       healthpb.HealthCheckResponse_SERVING.String(),
+		Dependencies: make(map[string]DependencyHealth, len(depsCheck.Dependencies)),
+		Services:     make(map[string]string, len(depsCheck.Services)),
+	}
+
+	for name, check := range depsCheck.Dependencies {
+		if err := check(); err != nil {
+			snapshot.Dependencies[name] = DependencyHealth{Error: err.Error()}
+			snapshot.Status = healthpb.HealthCheckResponse_NOT_SERVING.String()
+
+			continue
+		}
+
+		snapshot.Dependencies[name] = DependencyHealth{Healthy: true}
+	}
+
+	for service, deps := range depsCheck.Services {
+		snapshot.Services[service] = healthpb.HealthCheckResponse_SERVING.String()
+
+		for _, dep := range deps {
+			if !snapshot.Dependencies[dep].Healthy {
+				snapshot.Services[service] = healthpb.HealthCheckResponse_NOT_SERVING.String()
+				break
+			}
+		}
+	}
+
+	return snapshot
+}
@@ -26,7 +26,7 @@ func TestHealthServerOK(t *testing.T) {
 		},
 	}
 
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -40,7 +40,7 @@ func TestHealthServerOK(t *testing.T) {
 	connPool := arpc.NewConnPool(false)
 	defer connPool.Close()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", arpc.ListenerPort(listener), arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := healthpb.NewHealthClient(conn)
@@ -70,7 +70,7 @@ func TestHealthServerUnknownService(t *testing.T) {
 		},
 	}
 
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -84,7 +84,7 @@ func TestHealthServerUnknownService(t *testing.T) {
 	connPool := arpc.NewConnPool(false)
 	defer connPool.Close()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", arpc.ListenerPort(listener), arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := healthpb.NewHealthClient(conn)
@@ -105,7 +105,7 @@ func TestHealthServerUnknownDependency(t *testing.T) {
 		},
 	}
 
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -119,7 +119,7 @@ func TestHealthServerUnknownDependency(t *testing.T) {
 	connPool := arpc.NewConnPool(false)
 	defer connPool.Close()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", arpc.ListenerPort(listener), arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := healthpb.NewHealthClient(conn)
@@ -143,7 +143,7 @@ func TestHealthServerKO(t *testing.T) {
 		},
 	}
 
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -157,7 +157,7 @@ func TestHealthServerKO(t *testing.T) {
 	connPool := arpc.NewConnPool(false)
 	defer connPool.Close()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", arpc.ListenerPort(listener), arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := healthpb.NewHealthClient(conn)
@@ -198,7 +198,7 @@ func TestHealthServerWatch(t *testing.T) {
 		},
 	}
 
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -221,7 +221,7 @@ func TestHealthServerWatch(t *testing.T) {
 	ctxService2, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", arpc.ListenerPort(listener), arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := healthpb.NewHealthClient(conn)
@@ -287,7 +287,7 @@ func TestHealthServerWatchError(t *testing.T) {
 		},
 	}
 
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -304,7 +304,7 @@ func TestHealthServerWatchError(t *testing.T) {
 	ctxAll, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", arpc.ListenerPort(listener), arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := healthpb.NewHealthClient(conn)
@@ -316,3 +316,30 @@ func TestHealthServerWatchError(t *testing.T) {
 	_, err = streamAll.Recv()
 	testutils.RequireGRPCCodesEqual(t, err, codes.Canceled)
 }
+
+func TestDepsCheckSnapshot(t *testing.T) {
+	depsCheck := &arpc.DepsCheck{
+		Dependencies: arpc.DepCheckCallbacks{
+			"dep1": func() error { return nil },
+			"dep2": func() error { return errors.New("uwups") },
+		},
+		Services: arpc.DepCheckServices{
+			"service1": {"dep1"},
+			"service2": {"dep1", "dep2"},
+			"service3": {},
+		},
+	}
+
+	require.Equal(t, &arpc.HealthSnapshot{
+		Status: "NOT_SERVING",
+		Dependencies: map[string]arpc.DependencyHealth{
+			"dep1": {Healthy: true},
+			"dep2": {Error: "uwups"},
+		},
+		Services: map[string]string{
+			"service1": "SERVING",
+			"service2": "NOT_SERVING",
+			"service3": "SERVING",
+		},
+	}, depsCheck.Snapshot())
+}
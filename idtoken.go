@@ -0,0 +1,514 @@
+package arpc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GoogleCertsURL is the JWKS endpoint exposing the public keys used by Google to sign ID tokens.
+const GoogleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// GoogleIssuers are the issuers of Google ID tokens.
+var GoogleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+var (
+	ErrMissingToken     = errors.New("missing bearer token")
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrEmailNotAllowed  = errors.New("email not allowed")
+)
+
+// KeySource retrieves the public keys used to verify the signature of ID tokens.
+type KeySource interface {
+	// PublicKey returns the public key with the given ID.
+	PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error)
+}
+
+// StaticKeySource is a fixed set of public keys, indexed by their ID.
+type StaticKeySource map[string]crypto.PublicKey
+
+func (source StaticKeySource) PublicKey(_ context.Context, keyID string) (crypto.PublicKey, error) {
+	key, ok := source[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	return key, nil
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBigInt(src string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(src)
+	if err != nil {
+		return nil, fmt.Errorf("decode base64: %w", err)
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func (key *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode exponent: %w", err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if key.Crv != "P-256" {
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedAlg, key.Crv)
+		}
+
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("%w: key type %s", ErrUnsupportedAlg, key.Kty)
+	}
+}
+
+// ParseJWKS parses a JSON Web Key Set. Keys of unsupported types are ignored.
+func ParseJWKS(data []byte) (StaticKeySource, error) {
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return nil, fmt.Errorf("unmarshal key set: %w", err)
+	}
+
+	output := make(StaticKeySource, len(keySet.Keys))
+
+	for _, key := range keySet.Keys {
+		publicKey, err := key.publicKey()
+		if errors.Is(err, ErrUnsupportedAlg) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parse key %s: %w", key.Kid, err)
+		}
+
+		output[key.Kid] = publicKey
+	}
+
+	return output, nil
+}
+
+// Minimum interval between refreshes of a JWKS key source caused by unknown keys, so tokens with random key IDs
+// can't flood the endpoint.
+const jwksMinForcedRefreshInterval = time.Minute
+
+// Timeout of a fetch of a JWKS key set. Fetches are shared by callers, so they don't run on the context of any of them.
+const jwksFetchTimeout = 10 * time.Second
+
+// jwksFetch is a fetch of the key set, shared by every caller waiting for it.
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+type jwksKeySource struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+
+	keys      StaticKeySource
+	fetchedAt time.Time
+	forcedAt  time.Time
+	inflight  *jwksFetch
+
+	mu sync.Mutex
+}
+
+func (source *jwksKeySource) fetch(ctx context.Context) (StaticKeySource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := source.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch key set: unexpected status %s", resp.Status)
+	}
+
+	var raw json.RawMessage
+	if err = json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("read key set: %w", err)
+	}
+
+	keys, err := ParseJWKS(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse key set: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Fetches the key set in the background, without holding the lock. Concurrent callers wait for the same fetch, and
+// can each give up with their own context.
+func (source *jwksKeySource) refresh(ctx context.Context, forced bool) error {
+	current := source.inflight
+	if current == nil {
+		current = &jwksFetch{done: make(chan struct{})}
+		source.inflight = current
+
+		if forced {
+			source.forcedAt = time.Now()
+		}
+
+		go func() {
+			fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+			defer cancel()
+
+			keys, err := source.fetch(fetchCtx)
+
+			source.mu.Lock()
+			defer source.mu.Unlock()
+
+			if err == nil {
+				source.keys = keys
+				source.fetchedAt = time.Now()
+			}
+
+			current.err = err
+			source.inflight = nil
+			close(current.done)
+		}()
+	}
+
+	source.mu.Unlock()
+	defer source.mu.Lock()
+
+	select {
+	case <-current.done:
+		return current.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (source *jwksKeySource) PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	// Keys are rotated regularly, so an unknown key is a good reason to refresh the cache early.
+	_, known := source.keys[keyID]
+	expired := time.Since(source.fetchedAt) > source.ttl
+	forced := !known && !expired && time.Since(source.forcedAt) >= jwksMinForcedRefreshInterval
+
+	if expired || forced {
+		if err := source.refresh(ctx, forced); err != nil {
+			return nil, err
+		}
+	}
+
+	return source.keys.PublicKey(ctx, keyID)
+}
+
+// NewJWKSKeySource creates a KeySource that fetches keys from a remote JSON Web Key Set, such as GoogleCertsURL.
+// Keys are cached for the given duration. Unknown keys refresh the cache early, at most once a minute. A nil client
+// defaults to http.DefaultClient.
+func NewJWKSKeySource(url string, client *http.Client, ttl time.Duration) KeySource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &jwksKeySource{url: url, client: client, ttl: ttl}
+}
+
+// IDTokenClaims are the verified claims of an ID token.
+type IDTokenClaims struct {
+	Issuer        string
+	Subject       string
+	Audience      []string
+	Email         string
+	EmailVerified bool
+	ExpiresAt     time.Time
+	IssuedAt      time.Time
+	NotBefore     time.Time
+
+	// Raw contains every claim of the token, including the ones above.
+	Raw map[string]interface{}
+}
+
+type idTokenClaimsKey struct{}
+
+// IDTokenClaimsFromContext returns the claims verified by the ID token interceptors.
+func IDTokenClaimsFromContext(ctx context.Context) (*IDTokenClaims, bool) {
+	claims, ok := ctx.Value(idTokenClaimsKey{}).(*IDTokenClaims)
+	return claims, ok
+}
+
+// IDTokenConfig configures the verification of ID tokens.
+type IDTokenConfig struct {
+	// Keys is used to verify token signatures. Use NewJWKSKeySource(GoogleCertsURL, nil, time.Hour) for
+	// Google ID tokens.
+	Keys KeySource
+	// Audiences lists accepted audiences. A token is valid if any of its audiences is listed. For Cloud Run services,
+	// this is the URL of the service, without port.
+	Audiences []string
+	// Issuers lists accepted issuers. Defaults to GoogleIssuers.
+	Issuers []string
+	// AllowedEmails restricts the service accounts allowed to call the server. Any email is accepted when empty.
+	AllowedEmails []string
+	// Leeway tolerates some clock skew when checking expiration and not before dates.
+	Leeway time.Duration
+	// Skip lists method patterns that do not require authentication, following the syntax of path.Match.
+	Skip []string
+}
+
+func decodeSegment(segment string, dst interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedToken, err)
+	}
+
+	if err = json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedToken, err)
+	}
+
+	return nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key does not match algorithm %s", ErrInvalidSignature, alg)
+		}
+
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return fmt.Errorf("%w: key does not match algorithm %s", ErrInvalidSignature, alg)
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+
+	return nil
+}
+
+func numericDate(raw map[string]interface{}, key string) time.Time {
+	value, ok := raw[key].(float64)
+	if !ok {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(value), 0)
+}
+
+func parseClaims(raw map[string]interface{}) *IDTokenClaims {
+	claims := &IDTokenClaims{Raw: raw}
+
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Email, _ = raw["email"].(string)
+	claims.EmailVerified, _ = raw["email_verified"].(bool)
+	claims.ExpiresAt = numericDate(raw, "exp")
+	claims.IssuedAt = numericDate(raw, "iat")
+	claims.NotBefore = numericDate(raw, "nbf")
+
+	// Audience may be a single string, or an array of strings.
+	switch audience := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{audience}
+	case []interface{}:
+		for _, item := range audience {
+			if value, ok := item.(string); ok {
+				claims.Audience = append(claims.Audience, value)
+			}
+		}
+	}
+
+	return claims
+}
+
+// VerifyIDToken checks the signature and claims of a raw ID token, and returns its claims.
+func (config *IDTokenConfig) VerifyIDToken(ctx context.Context, token string) (*IDTokenClaims, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err := decodeSegment(segments[0], &header); err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode signature: %w", ErrMalformedToken, err)
+	}
+
+	key, err := config.Keys.PublicKey(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	if err = verifySignature(header.Alg, key, []byte(segments[0]+"."+segments[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err = decodeSegment(segments[1], &raw); err != nil {
+		return nil, fmt.Errorf("decode claims: %w", err)
+	}
+
+	claims := parseClaims(raw)
+
+	if claims.ExpiresAt.IsZero() || time.Now().After(claims.ExpiresAt.Add(config.Leeway)) {
+		return nil, ErrTokenExpired
+	}
+
+	if !claims.NotBefore.IsZero() && time.Now().Add(config.Leeway).Before(claims.NotBefore) {
+		return nil, ErrTokenNotYetValid
+	}
+
+	issuers := config.Issuers
+	if len(issuers) == 0 {
+		issuers = GoogleIssuers
+	}
+
+	if !slices.Contains(issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIssuer, claims.Issuer)
+	}
+
+	if !slices.ContainsFunc(claims.Audience, func(audience string) bool {
+		return slices.Contains(config.Audiences, audience)
+	}) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAudience, claims.Audience)
+	}
+
+	if len(config.AllowedEmails) > 0 {
+		if !claims.EmailVerified || !slices.Contains(config.AllowedEmails, claims.Email) {
+			return nil, fmt.Errorf("%w: %s", ErrEmailNotAllowed, claims.Email)
+		}
+	}
+
+	return claims, nil
+}
+
+func (config *IDTokenConfig) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if matchAnyPattern(config.Skip, fullMethod) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	authorization := md.Get("authorization")
+	if len(authorization) == 0 {
+		return nil, status.Error(codes.Unauthenticated, ErrMissingToken.Error()) //nolint:wrapcheck
+	}
+
+	scheme, token, ok := strings.Cut(authorization[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return nil, status.Error(codes.Unauthenticated, ErrMissingToken.Error()) //nolint:wrapcheck
+	}
+
+	claims, err := config.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "verify token: %s", err)
+	}
+
+	return context.WithValue(ctx, idTokenClaimsKey{}, claims), nil
+}
+
+// UnaryIDTokenInterceptor rejects unary calls that do not carry a valid ID token, with codes.Unauthenticated.
+// Verified claims are available to handlers through IDTokenClaimsFromContext.
+func UnaryIDTokenInterceptor(config *IDTokenConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, err := config.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamIDTokenInterceptor rejects streams that do not carry a valid ID token, with codes.Unauthenticated.
+// Verified claims are available to handlers through IDTokenClaimsFromContext.
+func StreamIDTokenInterceptor(config *IDTokenConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := config.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
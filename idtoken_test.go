@@ -0,0 +1,340 @@
+package arpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func idTokenClaims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":            "https://accounts.google.com",
+		"sub":            "1234",
+		"aud":            "https://my-service.run.app",
+		"email":          "caller@project.iam.gserviceaccount.com",
+		"email_verified": true,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+
+	for key, value := range overrides {
+		claims[key] = value
+	}
+
+	return claims
+}
+
+func TestIDTokenInterceptors(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	config := &arpc.IDTokenConfig{
+		Keys:          arpc.StaticKeySource{"key-1": &key.PublicKey},
+		Audiences:     []string{"https://my-service.run.app"},
+		AllowedEmails: []string{"caller@project.iam.gserviceaccount.com"},
+		Skip:          []string{"/grpc.testing.TestService/UnaryCall"},
+		Leeway:        30 * time.Second,
+	}
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(ctx context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			claims, ok := arpc.IDTokenClaimsFromContext(ctx)
+			if !ok {
+				return nil, status.Error(codes.DataLoss, "missing claims")
+			}
+
+			if claims.Email != "caller@project.iam.gserviceaccount.com" {
+				return nil, status.Errorf(codes.DataLoss, "unexpected email %s", claims.Email)
+			}
+
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryIDTokenInterceptor(config)),
+		grpc.ChainStreamInterceptor(arpc.StreamIDTokenInterceptor(config)),
+	}))
+	defer stub.Stop()
+
+	sign := func(key *rsa.PrivateKey, keyID string, overrides map[string]interface{}) string {
+		token, err := arpcmocks.SignIDToken(key, keyID, idTokenClaims(overrides))
+		require.NoError(t, err)
+
+		return "Bearer " + token
+	}
+
+	testCases := []struct {
+		name string
+
+		authorization string
+
+		expectCode codes.Code
+	}{
+		{
+			name: "OK",
+
+			authorization: sign(key, "key-1", nil),
+
+			expectCode: codes.OK,
+		},
+		{
+			name: "MultipleAudiences",
+
+			authorization: sign(key, "key-1", map[string]interface{}{
+				"aud": []string{"https://other.run.app", "https://my-service.run.app"},
+			}),
+
+			expectCode: codes.OK,
+		},
+		{
+			name: "MissingToken",
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "WrongScheme",
+
+			authorization: "Basic foo",
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "Malformed",
+
+			authorization: "Bearer foo.bar",
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "UnknownKey",
+
+			authorization: sign(key, "key-2", nil),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "WrongSignature",
+
+			authorization: sign(otherKey, "key-1", nil),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "Expired",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "NotYetValid",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"nbf": time.Now().Add(10 * time.Minute).Unix()}),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "NotBeforeWithinLeeway",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"nbf": time.Now().Add(10 * time.Second).Unix()}),
+
+			expectCode: codes.OK,
+		},
+		{
+			name: "WrongAudience",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"aud": "https://other.run.app"}),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "WrongIssuer",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"iss": "https://evil.com"}),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "EmailNotAllowed",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"email": "other@project.iam.gserviceaccount.com"}),
+
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "EmailNotVerified",
+
+			authorization: sign(key, "key-1", map[string]interface{}{"email_verified": false}),
+
+			expectCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if testCase.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", testCase.authorization)
+			}
+
+			_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+			require.Equal(t, testCase.expectCode, status.Code(err), err)
+
+			// Skipped methods never require authentication.
+			_, err = stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJWKSKeySource(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	keySet, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]interface{}{
+			{
+				"kid": "rsa",
+				"kty": "RSA",
+				"n":   encode(rsaKey.N),
+				"e":   encode(big.NewInt(int64(rsaKey.E))),
+			},
+			{
+				"kid": "ec",
+				"kty": "EC",
+				"crv": "P-256",
+				"x":   encode(ecKey.X),
+				"y":   encode(ecKey.Y),
+			},
+			{
+				"kid": "unsupported",
+				"kty": "oct",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		_, _ = w.Write(keySet)
+	}))
+	defer server.Close()
+
+	source := arpc.NewJWKSKeySource(server.URL, server.Client(), time.Hour)
+
+	key, err := source.PublicKey(context.Background(), "rsa")
+	require.NoError(t, err)
+	require.True(t, rsaKey.PublicKey.Equal(key))
+
+	key, err = source.PublicKey(context.Background(), "ec")
+	require.NoError(t, err)
+	require.True(t, ecKey.PublicKey.Equal(key))
+
+	// Known keys are cached.
+	require.Equal(t, 1, calls)
+
+	// Unknown keys trigger a refresh.
+	_, err = source.PublicKey(context.Background(), "unsupported")
+	require.ErrorIs(t, err, arpc.ErrUnknownKey)
+	require.Equal(t, 2, calls)
+
+	// Refreshes caused by unknown keys are rate limited.
+	for range 10 {
+		_, err = source.PublicKey(context.Background(), "random")
+		require.ErrorIs(t, err, arpc.ErrUnknownKey)
+	}
+
+	require.Equal(t, 2, calls)
+
+	// Concurrent callers share a single fetch of an expired key set.
+	concurrentCalls := new(atomic.Int32)
+	release := make(chan struct{})
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		concurrentCalls.Add(1)
+		<-release
+		_, _ = w.Write(keySet)
+	}))
+	defer slowServer.Close()
+
+	slowSource := arpc.NewJWKSKeySource(slowServer.URL, slowServer.Client(), time.Hour)
+
+	// The first caller starts the fetch, then gives up. The fetch goes on for the other callers.
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+
+	go func() {
+		_, keyErr := slowSource.PublicKey(firstCtx, "rsa")
+		firstErr <- keyErr
+	}()
+
+	require.Eventually(t, func() bool { return concurrentCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	cancelFirst()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+
+	wg := new(sync.WaitGroup)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, keyErr := slowSource.PublicKey(context.Background(), "rsa")
+			assert.NoError(t, keyErr)
+		}()
+	}
+
+	// The lock is not held during the fetch, so waiting callers can give up.
+	waitCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = slowSource.PublicKey(waitCtx, "rsa")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), concurrentCalls.Load())
+}
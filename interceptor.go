@@ -0,0 +1,44 @@
+package arpc
+
+import (
+	"context"
+	"path"
+
+	"google.golang.org/grpc"
+)
+
+// matchPattern reports whether a value, such as a full GRPC method name (/package.Service/Method), matches a pattern.
+//
+// Patterns use the syntax of path.Match, so "/package.Service/*" matches every method of a service, and "*" alone
+// matches everything.
+func matchPattern(pattern, value string) bool {
+	if pattern == "*" || pattern == value {
+		return true
+	}
+
+	ok, err := path.Match(pattern, value)
+
+	return err == nil && ok
+}
+
+// matchAnyPattern reports whether a value matches at least one of the patterns.
+func matchAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contextServerStream overrides the context of a server stream, so interceptors can pass values to stream handlers.
+type contextServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context //nolint:containedctx
+}
+
+func (stream *contextServerStream) Context() context.Context {
+	return stream.ctx
+}
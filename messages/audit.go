@@ -0,0 +1,55 @@
+package arpcmessages
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/samber/lo"
+
+	"github.com/a-novel-kit/quicklog"
+)
+
+type auditMessage struct {
+	service   string
+	principal string
+	rule      string
+	allowed   bool
+
+	quicklog.Message
+}
+
+func (audit *auditMessage) RenderTerminal() string {
+	color := lipgloss.Color(lo.Ternary(audit.allowed, "33", "202"))
+	decision := lo.Ternary(audit.allowed, "🔓 ALLOW", "🔒 DENY")
+
+	principal := lo.CoalesceOrEmpty(audit.principal, "anonymous")
+	rule := lo.CoalesceOrEmpty(audit.rule, "default")
+
+	return lipgloss.NewStyle().Foreground(color).Bold(true).Render(decision) +
+		lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf(" [%s] %s", audit.service, principal)) +
+		lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" (rule: %s)", rule)) +
+		"\n\n"
+}
+
+func (audit *auditMessage) RenderJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"severity": lo.Ternary(audit.allowed, "INFO", "WARNING"),
+		"audit": map[string]interface{}{
+			"service":   audit.service,
+			"principal": audit.principal,
+			"rule":      audit.rule,
+			"allowed":   audit.allowed,
+		},
+	}
+}
+
+// NewAudit creates a new message for an authorization decision. An empty rule means the decision was made by
+// the default policy.
+func NewAudit(service, principal, rule string, allowed bool) quicklog.Message {
+	return &auditMessage{
+		service:   service,
+		principal: principal,
+		rule:      rule,
+		allowed:   allowed,
+	}
+}
@@ -0,0 +1,70 @@
+package arpcmessages_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+func TestAudit(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		service   string
+		principal string
+		rule      string
+		allowed   bool
+
+		expectConsole string
+		expectJSON    interface{}
+	}{
+		{
+			name: "Allow",
+
+			service:   "/users.Users/Delete",
+			principal: "admin@project.iam.gserviceaccount.com",
+			rule:      "admins",
+			allowed:   true,
+
+			expectConsole: "🔓 ALLOW [/users.Users/Delete] admin@project.iam.gserviceaccount.com (rule: admins)\n\n",
+			expectJSON: map[string]interface{}{
+				"severity": "INFO",
+				"audit": map[string]interface{}{
+					"service":   "/users.Users/Delete",
+					"principal": "admin@project.iam.gserviceaccount.com",
+					"rule":      "admins",
+					"allowed":   true,
+				},
+			},
+		},
+		{
+			name: "DenyByDefault",
+
+			service:   "/users.Users/Delete",
+			principal: "",
+			rule:      "",
+			allowed:   false,
+
+			expectConsole: "🔒 DENY [/users.Users/Delete] anonymous (rule: default)\n\n",
+			expectJSON: map[string]interface{}{
+				"severity": "WARNING",
+				"audit": map[string]interface{}{
+					"service":   "/users.Users/Delete",
+					"principal": "",
+					"rule":      "",
+					"allowed":   false,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			message := arpcmessages.NewAudit(testCase.service, testCase.principal, testCase.rule, testCase.allowed)
+			require.Equal(t, testCase.expectConsole, message.RenderTerminal())
+			require.Equal(t, testCase.expectJSON, message.RenderJSON())
+		})
+	}
+}
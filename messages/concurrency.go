@@ -0,0 +1,40 @@
+package arpcmessages
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/a-novel-kit/quicklog"
+)
+
+type concurrencyLimitMessage struct {
+	limit    int
+	inFlight int
+
+	quicklog.Message
+}
+
+func (message *concurrencyLimitMessage) RenderTerminal() string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Bold(true).Render("⚠ Concurrency limit lowered") +
+		lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" (limit: %d, in flight: %d)", message.limit, message.inFlight)) +
+		"\n\n"
+}
+
+func (message *concurrencyLimitMessage) RenderJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"severity": "WARNING",
+		"concurrency": map[string]interface{}{
+			"limit":    message.limit,
+			"inFlight": message.inFlight,
+		},
+	}
+}
+
+// NewConcurrencyLimit creates a new message reporting the current concurrency limit of a server.
+func NewConcurrencyLimit(limit, inFlight int) quicklog.Message {
+	return &concurrencyLimitMessage{
+		limit:    limit,
+		inFlight: inFlight,
+	}
+}
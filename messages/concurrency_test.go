@@ -0,0 +1,22 @@
+package arpcmessages_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	message := arpcmessages.NewConcurrencyLimit(12, 11)
+
+	require.Equal(t, "⚠ Concurrency limit lowered (limit: 12, in flight: 11)\n\n", message.RenderTerminal())
+	require.Equal(t, map[string]interface{}{
+		"severity": "WARNING",
+		"concurrency": map[string]interface{}{
+			"limit":    12,
+			"inFlight": 11,
+		},
+	}, message.RenderJSON())
+}
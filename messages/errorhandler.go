@@ -0,0 +1,118 @@
+package arpcmessages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/grpc/codes"
+
+	"github.com/a-novel-kit/quicklog"
+)
+
+// ErrorMapping describes a case of an error handler.
+type ErrorMapping struct {
+	// Case is the name of the case.
+	Case string
+	// Match describes the errors matched by the case.
+	Match string
+	// Code is the code of the statuses produced by the case, or "dynamic" if it is decided at runtime.
+	Code string
+}
+
+type errorMappingsMessage struct {
+	name     string
+	mappings []ErrorMapping
+
+	quicklog.Message
+}
+
+func (message *errorMappingsMessage) RenderTerminal() string {
+	caseWidth, matchWidth := 0, 0
+	for _, mapping := range message.mappings {
+		caseWidth = max(caseWidth, len(mapping.Case))
+		matchWidth = max(matchWidth, len(mapping.Match))
+	}
+
+	var rows strings.Builder
+	for _, mapping := range message.mappings {
+		rows.WriteString(lipgloss.NewStyle().MarginLeft(2).Render(
+			fmt.Sprintf("%-*s  %-*s  → ", caseWidth, mapping.Case, matchWidth, mapping.Match),
+		))
+		rows.WriteString(lipgloss.NewStyle().Bold(true).Render(mapping.Code))
+		rows.WriteString("\n")
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true).Render("🗺 Error mappings") +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Render(fmt.Sprintf(" [%s]", message.name)) +
+		"\n" + rows.String() + "\n"
+}
+
+func (message *errorMappingsMessage) RenderJSON() map[string]interface{} {
+	mappings := make([]map[string]interface{}, len(message.mappings))
+	for i, mapping := range message.mappings {
+		mappings[i] = map[string]interface{}{
+			"case":  mapping.Case,
+			"match": mapping.Match,
+			"code":  mapping.Code,
+		}
+	}
+
+	return map[string]interface{}{
+		"severity":     "INFO",
+		"errorHandler": message.name,
+		"mappings":     mappings,
+	}
+}
+
+// NewErrorMappings creates a new message, listing the cases of an error handler. Log it at startup, so the mapping
+// of errors to codes can be reviewed.
+func NewErrorMappings(name string, mappings []ErrorMapping) quicklog.Message {
+	return &errorMappingsMessage{
+		name:     name,
+		mappings: mappings,
+	}
+}
+
+type errorTraceMessage struct {
+	err       error
+	evaluated []string
+	matched   string
+	code      codes.Code
+
+	quicklog.Message
+}
+
+func (message *errorTraceMessage) RenderTerminal() string {
+	evaluatedMessage := ""
+	if len(message.evaluated) > 0 {
+		evaluatedMessage = "\n" + lipgloss.NewStyle().MarginLeft(2).Faint(true).
+			Render("evaluated: "+strings.Join(message.evaluated, ", "))
+	}
+
+	return lipgloss.NewStyle().Bold(true).Render("🔍 "+message.code.String()) +
+		lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" [%s]", message.matched)) +
+		"\n" + lipgloss.NewStyle().MarginLeft(2).Render(fmt.Sprint(message.err)) +
+		evaluatedMessage +
+		"\n\n"
+}
+
+func (message *errorTraceMessage) RenderJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"severity":  "DEBUG",
+		"error":     fmt.Sprint(message.err),
+		"code":      message.code,
+		"matched":   message.matched,
+		"evaluated": message.evaluated,
+	}
+}
+
+// NewErrorTrace creates a new message, describing how an error handler handled an error.
+func NewErrorTrace(err error, evaluated []string, matched string, code codes.Code) quicklog.Message {
+	return &errorTraceMessage{
+		err:       err,
+		evaluated: evaluated,
+		matched:   matched,
+		code:      code,
+	}
+}
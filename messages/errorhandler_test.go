@@ -0,0 +1,53 @@
+package arpcmessages_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+func TestErrorMappings(t *testing.T) {
+	message := arpcmessages.NewErrorMappings("books", []arpcmessages.ErrorMapping{
+		{Case: "database/no rows", Match: "is no rows", Code: "NotFound"},
+		{Case: "default", Match: "any", Code: "Internal"},
+	})
+
+	require.Equal(
+		t,
+		"🗺 Error mappings [books]\n"+
+			"  database/no rows  is no rows  → NotFound\n"+
+			"  default           any         → Internal\n\n",
+		message.RenderTerminal(),
+	)
+	require.Equal(t, map[string]interface{}{
+		"severity":     "INFO",
+		"errorHandler": "books",
+		"mappings": []map[string]interface{}{
+			{"case": "database/no rows", "match": "is no rows", "code": "NotFound"},
+			{"case": "default", "match": "any", "code": "Internal"},
+		},
+	}, message.RenderJSON())
+}
+
+func TestErrorTrace(t *testing.T) {
+	message := arpcmessages.NewErrorTrace(
+		errors.New("no rows"), []string{"book not found", "database/no rows"}, "database/no rows", codes.NotFound,
+	)
+
+	require.Equal(
+		t,
+		"🔍 NotFound [database/no rows]\n  no rows\n  evaluated: book not found, database/no rows\n\n",
+		message.RenderTerminal(),
+	)
+	require.Equal(t, map[string]interface{}{
+		"severity":  "DEBUG",
+		"error":     "no rows",
+		"code":      codes.NotFound,
+		"matched":   "database/no rows",
+		"evaluated": []string{"book not found", "database/no rows"},
+	}, message.RenderJSON())
+}
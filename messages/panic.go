@@ -0,0 +1,45 @@
+package arpcmessages
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/a-novel-kit/quicklog"
+)
+
+type panicMessage struct {
+	service string
+	value   interface{}
+	stack   []byte
+
+	quicklog.Message
+}
+
+func (message *panicMessage) RenderTerminal() string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true).Render("💥 PANIC") +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(fmt.Sprintf(" [%s]", message.service)) +
+		"\n" + lipgloss.NewStyle().MarginLeft(2).Foreground(lipgloss.Color("9")).Render(fmt.Sprint(message.value)) +
+		"\n" + lipgloss.NewStyle().MarginLeft(2).Faint(true).Render(string(message.stack)) +
+		"\n\n"
+}
+
+func (message *panicMessage) RenderJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"severity": "CRITICAL",
+		"grpcRequest": map[string]interface{}{
+			"service": message.service,
+		},
+		"error":      fmt.Sprint(message.value),
+		"stackTrace": string(message.stack),
+	}
+}
+
+// NewPanic creates a new message for a panic recovered while serving a GRPC request.
+func NewPanic(service string, value interface{}, stack []byte) quicklog.Message {
+	return &panicMessage{
+		service: service,
+		value:   value,
+		stack:   stack,
+	}
+}
@@ -0,0 +1,27 @@
+package arpcmessages_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+func TestPanic(t *testing.T) {
+	message := arpcmessages.NewPanic("MyService", "uwups", []byte("goroutine 1 [running]:"))
+
+	require.Equal(
+		t,
+		"💥 PANIC [MyService]\n  uwups\n  goroutine 1 [running]:\n\n",
+		message.RenderTerminal(),
+	)
+	require.Equal(t, map[string]interface{}{
+		"severity": "CRITICAL",
+		"grpcRequest": map[string]interface{}{
+			"service": "MyService",
+		},
+		"error":      "uwups",
+		"stackTrace": "goroutine 1 [running]:",
+	}, message.RenderJSON())
+}
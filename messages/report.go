@@ -14,12 +14,55 @@ import (
 
 type Metrics struct {
 	Latency time.Duration
+
+	// Stream is set for streaming RPCs. Latency then represents the total duration of the stream.
+	Stream           bool
+	MessagesSent     int
+	MessagesReceived int
+}
+
+func (metrics *Metrics) String() string {
+	if metrics.Stream {
+		return fmt.Sprintf(
+			"%s, %d sent, %d received",
+			metrics.Latency, metrics.MessagesSent, metrics.MessagesReceived,
+		)
+	}
+
+	return metrics.Latency.String()
+}
+
+// ReportDetails carries optional information about a request.
+type ReportDetails struct {
+	// RequestID identifies the request, so reports can be correlated with client side failures.
+	RequestID string
+	// ServerDeadline is the deadline imposed by the server, when it cut the call. It is zero otherwise.
+	ServerDeadline time.Duration
+}
+
+// ReportOption adds optional information to a report.
+type ReportOption func(details *ReportDetails)
+
+// WithRequestID adds the ID of the request to the report. Empty IDs are ignored.
+func WithRequestID(requestID string) ReportOption {
+	return func(details *ReportDetails) {
+		details.RequestID = requestID
+	}
+}
+
+// WithServerDeadline reports that the call was cut by a deadline imposed by the server, rather than by the client.
+// Zero durations are ignored.
+func WithServerDeadline(deadline time.Duration) ReportOption {
+	return func(details *ReportDetails) {
+		details.ServerDeadline = deadline
+	}
 }
 
 type reportMessage struct {
 	metrics *Metrics
 	service string
 	err     error
+	details ReportDetails
 
 	quicklog.Message
 }
@@ -33,7 +76,18 @@ func (report *reportMessage) RenderTerminal() string {
 
 	latencyMessage := ""
 	if report.metrics != nil {
-		latencyMessage = lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" (%s)", report.metrics.Latency))
+		latencyMessage = lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" (%s)", report.metrics))
+	}
+
+	requestMessage := ""
+	if report.details.RequestID != "" {
+		requestMessage = lipgloss.NewStyle().Faint(true).Render(" #" + report.details.RequestID)
+	}
+
+	deadlineMessage := ""
+	if report.details.ServerDeadline > 0 {
+		deadlineMessage = lipgloss.NewStyle().Faint(true).
+			Render(fmt.Sprintf(" (cut by server deadline of %s)", report.details.ServerDeadline))
 	}
 
 	code := status.Code(report.err)
@@ -60,6 +114,8 @@ func (report *reportMessage) RenderTerminal() string {
 		Render(prefix+code.String()) +
 		lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf(" [%s]", report.service)) +
 		latencyMessage +
+		requestMessage +
+		deadlineMessage +
 		errorMessage +
 		"\n\n"
 }
@@ -88,6 +144,20 @@ func (report *reportMessage) RenderJSON() map[string]interface{} {
 
 	if report.metrics != nil {
 		grpcRequest["latency"] = report.metrics.Latency
+
+		if report.metrics.Stream {
+			grpcRequest["stream"] = true
+			grpcRequest["messagesSent"] = report.metrics.MessagesSent
+			grpcRequest["messagesReceived"] = report.metrics.MessagesReceived
+		}
+	}
+
+	if report.details.RequestID != "" {
+		grpcRequest["requestId"] = report.details.RequestID
+	}
+
+	if report.details.ServerDeadline > 0 {
+		grpcRequest["serverDeadline"] = report.details.ServerDeadline
 	}
 
 	if report.err != nil {
@@ -98,10 +168,16 @@ func (report *reportMessage) RenderJSON() map[string]interface{} {
 }
 
 // NewReport creates a new report message.
-func NewReport(metrics *Metrics, service string, err error) quicklog.Message {
-	return &reportMessage{
+func NewReport(metrics *Metrics, service string, err error, options ...ReportOption) quicklog.Message {
+	report := &reportMessage{
 		metrics: metrics,
 		service: service,
 		err:     err,
 	}
+
+	for _, option := range options {
+		option(&report.details)
+	}
+
+	return report
 }
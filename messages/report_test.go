@@ -20,6 +20,7 @@ func TestReport(t *testing.T) {
 		metrics *arpcmessages.Metrics
 		service string
 		err     error
+		options []arpcmessages.ReportOption
 
 		expectConsole string
 		expectJSON    interface{}
@@ -161,11 +162,81 @@ func TestReport(t *testing.T) {
 				"severity": "INFO",
 			},
 		},
+		{
+			name: "WithStreamMetrics",
+
+			metrics: &arpcmessages.Metrics{
+				Latency:          3 * time.Second,
+				Stream:           true,
+				MessagesSent:     4,
+				MessagesReceived: 2,
+			},
+			service: "MyService",
+			err:     nil,
+
+			expectConsole: "✅ OK [MyService] (3s, 4 sent, 2 received)\n\n",
+			expectJSON: map[string]interface{}{
+				"grpcRequest": map[string]interface{}{
+					"code":             codes.OK,
+					"service":          "MyService",
+					"latency":          3 * time.Second,
+					"stream":           true,
+					"messagesSent":     4,
+					"messagesReceived": 2,
+				},
+				"severity": "INFO",
+			},
+		},
+		{
+			name: "WithRequestID",
+
+			metrics: &arpcmessages.Metrics{
+				Latency: 2*time.Second + 200*time.Millisecond,
+			},
+			service: "MyService",
+			err:     status.Error(codes.NotFound, "uwups"),
+			options: []arpcmessages.ReportOption{arpcmessages.WithRequestID("abc123")},
+
+			expectConsole: "❌ NotFound [MyService] (2.2s) #abc123\n  rpc error: code = NotFound desc = uwups\n\n",
+			expectJSON: map[string]interface{}{
+				"grpcRequest": map[string]interface{}{
+					"code":      codes.NotFound,
+					"service":   "MyService",
+					"latency":   2*time.Second + 200*time.Millisecond,
+					"requestId": "abc123",
+				},
+				"error":    "rpc error: code = NotFound desc = uwups",
+				"severity": "ERROR",
+			},
+		},
+		{
+			name: "WithServerDeadline",
+
+			metrics: &arpcmessages.Metrics{
+				Latency: 5 * time.Second,
+			},
+			service: "MyService",
+			err:     status.Error(codes.DeadlineExceeded, "uwups"),
+			options: []arpcmessages.ReportOption{arpcmessages.WithServerDeadline(5 * time.Second)},
+
+			expectConsole: "❌ DeadlineExceeded [MyService] (5s) (cut by server deadline of 5s)\n" +
+				"  rpc error: code = DeadlineExceeded desc = uwups\n\n",
+			expectJSON: map[string]interface{}{
+				"grpcRequest": map[string]interface{}{
+					"code":           codes.DeadlineExceeded,
+					"service":        "MyService",
+					"latency":        5 * time.Second,
+					"serverDeadline": 5 * time.Second,
+				},
+				"error":    "rpc error: code = DeadlineExceeded desc = uwups",
+				"severity": "ERROR",
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			message := arpcmessages.NewReport(testCase.metrics, testCase.service, testCase.err)
+			message := arpcmessages.NewReport(testCase.metrics, testCase.service, testCase.err, testCase.options...)
 			require.Equal(t, testCase.expectConsole, message.RenderTerminal())
 			require.Equal(t, testCase.expectJSON, message.RenderJSON())
 		})
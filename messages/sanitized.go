@@ -0,0 +1,42 @@
+package arpcmessages
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/grpc/codes"
+
+	"github.com/a-novel-kit/quicklog"
+)
+
+type sanitizedMessage struct {
+	errorID string
+	code    codes.Code
+	err     error
+
+	quicklog.Message
+}
+
+func (message *sanitizedMessage) RenderTerminal() string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true).Render("🔒 "+message.code.String()) +
+		lipgloss.NewStyle().Faint(true).Render(" #"+message.errorID) +
+		"\n" + lipgloss.NewStyle().MarginLeft(2).Foreground(lipgloss.Color("9")).Render(message.err.Error()) +
+		"\n\n"
+}
+
+func (message *sanitizedMessage) RenderJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"severity": "ERROR",
+		"errorId":  message.errorID,
+		"code":     message.code,
+		"error":    message.err.Error(),
+	}
+}
+
+// NewSanitizedError creates a new message for an error that was hidden from the client. The client only receives
+// the error ID, which can be used to look the message up.
+func NewSanitizedError(errorID string, code codes.Code, err error) quicklog.Message {
+	return &sanitizedMessage{
+		errorID: errorID,
+		code:    code,
+		err:     err,
+	}
+}
@@ -0,0 +1,27 @@
+package arpcmessages_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+func TestSanitizedError(t *testing.T) {
+	message := arpcmessages.NewSanitizedError("abc123", codes.Internal, errors.New("pq: connection refused"))
+
+	require.Equal(
+		t,
+		"🔒 Internal #abc123\n  pq: connection refused\n\n",
+		message.RenderTerminal(),
+	)
+	require.Equal(t, map[string]interface{}{
+		"severity": "ERROR",
+		"errorId":  "abc123",
+		"code":     codes.Internal,
+		"error":    "pq: connection refused",
+	}, message.RenderJSON())
+}
@@ -0,0 +1,278 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package arpcmocks
+
+import (
+	arpc "github.com/a-novel-kit/arpc"
+	mock "github.com/stretchr/testify/mock"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// MockErrorDecoder is an autogenerated mock type for the ErrorDecoder type
+type MockErrorDecoder struct {
+	mock.Mock
+}
+
+type MockErrorDecoder_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockErrorDecoder) EXPECT() *MockErrorDecoder_Expecter {
+	return &MockErrorDecoder_Expecter{mock: &_m.Mock}
+}
+
+// As provides a mock function with given fields: code, reason, constructor
+func (_m *MockErrorDecoder) As(code codes.Code, reason string, constructor func(*status.Status) error) arpc.ErrorDecoder {
+	ret := _m.Called(code, reason, constructor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for As")
+	}
+
+	var r0 arpc.ErrorDecoder
+	if rf, ok := ret.Get(0).(func(codes.Code, string, func(*status.Status) error) arpc.ErrorDecoder); ok {
+		r0 = rf(code, reason, constructor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorDecoder)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorDecoder_As_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'As'
+type MockErrorDecoder_As_Call struct {
+	*mock.Call
+}
+
+// As is a helper method to define mock.On call
+//   - code codes.Code
+//   - reason string
+//   - constructor func(*status.Status) error
+func (_e *MockErrorDecoder_Expecter) As(code interface{}, reason interface{}, constructor interface{}) *MockErrorDecoder_As_Call {
+	return &MockErrorDecoder_As_Call{Call: _e.mock.On("As", code, reason, constructor)}
+}
+
+func (_c *MockErrorDecoder_As_Call) Run(run func(code codes.Code, reason string, constructor func(*status.Status) error)) *MockErrorDecoder_As_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(codes.Code), args[1].(string), args[2].(func(*status.Status) error))
+	})
+	return _c
+}
+
+func (_c *MockErrorDecoder_As_Call) Return(_a0 arpc.ErrorDecoder) *MockErrorDecoder_As_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorDecoder_As_Call) RunAndReturn(run func(codes.Code, string, func(*status.Status) error) arpc.ErrorDecoder) *MockErrorDecoder_As_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Decode provides a mock function with given fields: err
+func (_m *MockErrorDecoder) Decode(err error) error {
+	ret := _m.Called(err)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(error) error); ok {
+		r0 = rf(err)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockErrorDecoder_Decode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Decode'
+type MockErrorDecoder_Decode_Call struct {
+	*mock.Call
+}
+
+// Decode is a helper method to define mock.On call
+//   - err error
+func (_e *MockErrorDecoder_Expecter) Decode(err interface{}) *MockErrorDecoder_Decode_Call {
+	return &MockErrorDecoder_Decode_Call{Call: _e.mock.On("Decode", err)}
+}
+
+func (_c *MockErrorDecoder_Decode_Call) Run(run func(err error)) *MockErrorDecoder_Decode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(error))
+	})
+	return _c
+}
+
+func (_c *MockErrorDecoder_Decode_Call) Return(_a0 error) *MockErrorDecoder_Decode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorDecoder_Decode_Call) RunAndReturn(run func(error) error) *MockErrorDecoder_Decode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Is provides a mock function with given fields: code, reason, target
+func (_m *MockErrorDecoder) Is(code codes.Code, reason string, target error) arpc.ErrorDecoder {
+	ret := _m.Called(code, reason, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Is")
+	}
+
+	var r0 arpc.ErrorDecoder
+	if rf, ok := ret.Get(0).(func(codes.Code, string, error) arpc.ErrorDecoder); ok {
+		r0 = rf(code, reason, target)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorDecoder)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorDecoder_Is_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Is'
+type MockErrorDecoder_Is_Call struct {
+	*mock.Call
+}
+
+// Is is a helper method to define mock.On call
+//   - code codes.Code
+//   - reason string
+//   - target error
+func (_e *MockErrorDecoder_Expecter) Is(code interface{}, reason interface{}, target interface{}) *MockErrorDecoder_Is_Call {
+	return &MockErrorDecoder_Is_Call{Call: _e.mock.On("Is", code, reason, target)}
+}
+
+func (_c *MockErrorDecoder_Is_Call) Run(run func(code codes.Code, reason string, target error)) *MockErrorDecoder_Is_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(codes.Code), args[1].(string), args[2].(error))
+	})
+	return _c
+}
+
+func (_c *MockErrorDecoder_Is_Call) Return(_a0 arpc.ErrorDecoder) *MockErrorDecoder_Is_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorDecoder_Is_Call) RunAndReturn(run func(codes.Code, string, error) arpc.ErrorDecoder) *MockErrorDecoder_Is_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StreamClientInterceptor provides a mock function with no fields
+func (_m *MockErrorDecoder) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamClientInterceptor")
+	}
+
+	var r0 grpc.StreamClientInterceptor
+	if rf, ok := ret.Get(0).(func() grpc.StreamClientInterceptor); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(grpc.StreamClientInterceptor)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorDecoder_StreamClientInterceptor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StreamClientInterceptor'
+type MockErrorDecoder_StreamClientInterceptor_Call struct {
+	*mock.Call
+}
+
+// StreamClientInterceptor is a helper method to define mock.On call
+func (_e *MockErrorDecoder_Expecter) StreamClientInterceptor() *MockErrorDecoder_StreamClientInterceptor_Call {
+	return &MockErrorDecoder_StreamClientInterceptor_Call{Call: _e.mock.On("StreamClientInterceptor")}
+}
+
+func (_c *MockErrorDecoder_StreamClientInterceptor_Call) Run(run func()) *MockErrorDecoder_StreamClientInterceptor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockErrorDecoder_StreamClientInterceptor_Call) Return(_a0 grpc.StreamClientInterceptor) *MockErrorDecoder_StreamClientInterceptor_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorDecoder_StreamClientInterceptor_Call) RunAndReturn(run func() grpc.StreamClientInterceptor) *MockErrorDecoder_StreamClientInterceptor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnaryClientInterceptor provides a mock function with no fields
+func (_m *MockErrorDecoder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnaryClientInterceptor")
+	}
+
+	var r0 grpc.UnaryClientInterceptor
+	if rf, ok := ret.Get(0).(func() grpc.UnaryClientInterceptor); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(grpc.UnaryClientInterceptor)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorDecoder_UnaryClientInterceptor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnaryClientInterceptor'
+type MockErrorDecoder_UnaryClientInterceptor_Call struct {
+	*mock.Call
+}
+
+// UnaryClientInterceptor is a helper method to define mock.On call
+func (_e *MockErrorDecoder_Expecter) UnaryClientInterceptor() *MockErrorDecoder_UnaryClientInterceptor_Call {
+	return &MockErrorDecoder_UnaryClientInterceptor_Call{Call: _e.mock.On("UnaryClientInterceptor")}
+}
+
+func (_c *MockErrorDecoder_UnaryClientInterceptor_Call) Run(run func()) *MockErrorDecoder_UnaryClientInterceptor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockErrorDecoder_UnaryClientInterceptor_Call) Return(_a0 grpc.UnaryClientInterceptor) *MockErrorDecoder_UnaryClientInterceptor_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorDecoder_UnaryClientInterceptor_Call) RunAndReturn(run func() grpc.UnaryClientInterceptor) *MockErrorDecoder_UnaryClientInterceptor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockErrorDecoder creates a new instance of MockErrorDecoder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockErrorDecoder(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockErrorDecoder {
+	mock := &MockErrorDecoder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
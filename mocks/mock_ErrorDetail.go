@@ -0,0 +1,83 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package arpcmocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	protoiface "google.golang.org/protobuf/runtime/protoiface"
+)
+
+// MockErrorDetail is an autogenerated mock type for the ErrorDetail type
+type MockErrorDetail struct {
+	mock.Mock
+}
+
+type MockErrorDetail_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockErrorDetail) EXPECT() *MockErrorDetail_Expecter {
+	return &MockErrorDetail_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function with given fields: err
+func (_m *MockErrorDetail) Execute(err error) protoiface.MessageV1 {
+	ret := _m.Called(err)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Execute")
+	}
+
+	var r0 protoiface.MessageV1
+	if rf, ok := ret.Get(0).(func(error) protoiface.MessageV1); ok {
+		r0 = rf(err)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(protoiface.MessageV1)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorDetail_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockErrorDetail_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - err error
+func (_e *MockErrorDetail_Expecter) Execute(err interface{}) *MockErrorDetail_Execute_Call {
+	return &MockErrorDetail_Execute_Call{Call: _e.mock.On("Execute", err)}
+}
+
+func (_c *MockErrorDetail_Execute_Call) Run(run func(err error)) *MockErrorDetail_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(error))
+	})
+	return _c
+}
+
+func (_c *MockErrorDetail_Execute_Call) Return(_a0 protoiface.MessageV1) *MockErrorDetail_Execute_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorDetail_Execute_Call) RunAndReturn(run func(error) protoiface.MessageV1) *MockErrorDetail_Execute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockErrorDetail creates a new instance of MockErrorDetail. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockErrorDetail(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockErrorDetail {
+	mock := &MockErrorDetail{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
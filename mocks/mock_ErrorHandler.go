@@ -4,9 +4,9 @@ package arpcmocks
 
 import (
 	arpc "github.com/a-novel-kit/arpc"
-	codes "google.golang.org/grpc/codes"
-
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
 	mock "github.com/stretchr/testify/mock"
+	codes "google.golang.org/grpc/codes"
 )
 
 // MockErrorHandler is an autogenerated mock type for the ErrorHandler type
@@ -22,6 +22,67 @@ func (_m *MockErrorHandler) EXPECT() *MockErrorHandler_Expecter {
 	return &MockErrorHandler_Expecter{mock: &_m.Mock}
 }
 
+// Add provides a mock function with given fields: cases
+func (_m *MockErrorHandler) Add(cases ...arpc.ErrorCase) arpc.ErrorHandler {
+	_va := make([]interface{}, len(cases))
+	for _i := range cases {
+		_va[_i] = cases[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(...arpc.ErrorCase) arpc.ErrorHandler); ok {
+		r0 = rf(cases...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockErrorHandler_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - cases ...arpc.ErrorCase
+func (_e *MockErrorHandler_Expecter) Add(cases ...interface{}) *MockErrorHandler_Add_Call {
+	return &MockErrorHandler_Add_Call{Call: _e.mock.On("Add",
+		append([]interface{}{}, cases...)...)}
+}
+
+func (_c *MockErrorHandler_Add_Call) Run(run func(cases ...arpc.ErrorCase)) *MockErrorHandler_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]arpc.ErrorCase, len(args)-0)
+		for i, a := range args[0:] {
+			if a != nil {
+				variadicArgs[i] = a.(arpc.ErrorCase)
+			}
+		}
+		run(variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Add_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Add_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Add_Call) RunAndReturn(run func(...arpc.ErrorCase) arpc.ErrorHandler) *MockErrorHandler_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // As provides a mock function with given fields: target, code
 func (_m *MockErrorHandler) As(target interface{}, code codes.Code) arpc.ErrorHandler {
 	ret := _m.Called(target, code)
@@ -71,6 +132,70 @@ func (_c *MockErrorHandler_As_Call) RunAndReturn(run func(interface{}, codes.Cod
 	return _c
 }
 
+// AsD provides a mock function with given fields: target, code, details
+func (_m *MockErrorHandler) AsD(target interface{}, code codes.Code, details ...arpc.ErrorDetail) arpc.ErrorHandler {
+	_va := make([]interface{}, len(details))
+	for _i := range details {
+		_va[_i] = details[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, target, code)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AsD")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(interface{}, codes.Code, ...arpc.ErrorDetail) arpc.ErrorHandler); ok {
+		r0 = rf(target, code, details...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_AsD_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AsD'
+type MockErrorHandler_AsD_Call struct {
+	*mock.Call
+}
+
+// AsD is a helper method to define mock.On call
+//   - target interface{}
+//   - code codes.Code
+//   - details ...arpc.ErrorDetail
+func (_e *MockErrorHandler_Expecter) AsD(target interface{}, code interface{}, details ...interface{}) *MockErrorHandler_AsD_Call {
+	return &MockErrorHandler_AsD_Call{Call: _e.mock.On("AsD",
+		append([]interface{}{target, code}, details...)...)}
+}
+
+func (_c *MockErrorHandler_AsD_Call) Run(run func(target interface{}, code codes.Code, details ...arpc.ErrorDetail)) *MockErrorHandler_AsD_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]arpc.ErrorDetail, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(arpc.ErrorDetail)
+			}
+		}
+		run(args[0].(interface{}), args[1].(codes.Code), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_AsD_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_AsD_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_AsD_Call) RunAndReturn(run func(interface{}, codes.Code, ...arpc.ErrorDetail) arpc.ErrorHandler) *MockErrorHandler_AsD_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AsW provides a mock function with given fields: target, code, wrap
 func (_m *MockErrorHandler) AsW(target interface{}, code codes.Code, wrap error) arpc.ErrorHandler {
 	ret := _m.Called(target, code, wrap)
@@ -182,6 +307,101 @@ func (_c *MockErrorHandler_AsWF_Call) RunAndReturn(run func(interface{}, codes.C
 	return _c
 }
 
+// Clone provides a mock function with no fields
+func (_m *MockErrorHandler) Clone() arpc.ErrorHandler {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clone")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func() arpc.ErrorHandler); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Clone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Clone'
+type MockErrorHandler_Clone_Call struct {
+	*mock.Call
+}
+
+// Clone is a helper method to define mock.On call
+func (_e *MockErrorHandler_Expecter) Clone() *MockErrorHandler_Clone_Call {
+	return &MockErrorHandler_Clone_Call{Call: _e.mock.On("Clone")}
+}
+
+func (_c *MockErrorHandler_Clone_Call) Run(run func()) *MockErrorHandler_Clone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Clone_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Clone_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Clone_Call) RunAndReturn(run func() arpc.ErrorHandler) *MockErrorHandler_Clone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Explain provides a mock function with given fields: err
+func (_m *MockErrorHandler) Explain(err error) *arpc.ErrorTrace {
+	ret := _m.Called(err)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Explain")
+	}
+
+	var r0 *arpc.ErrorTrace
+	if rf, ok := ret.Get(0).(func(error) *arpc.ErrorTrace); ok {
+		r0 = rf(err)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*arpc.ErrorTrace)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Explain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Explain'
+type MockErrorHandler_Explain_Call struct {
+	*mock.Call
+}
+
+// Explain is a helper method to define mock.On call
+//   - err error
+func (_e *MockErrorHandler_Expecter) Explain(err interface{}) *MockErrorHandler_Explain_Call {
+	return &MockErrorHandler_Explain_Call{Call: _e.mock.On("Explain", err)}
+}
+
+func (_c *MockErrorHandler_Explain_Call) Run(run func(err error)) *MockErrorHandler_Explain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(error))
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Explain_Call) Return(_a0 *arpc.ErrorTrace) *MockErrorHandler_Explain_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Explain_Call) RunAndReturn(run func(error) *arpc.ErrorTrace) *MockErrorHandler_Explain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Handle provides a mock function with given fields: err
 func (_m *MockErrorHandler) Handle(err error) error {
 	ret := _m.Called(err)
@@ -228,6 +448,115 @@ func (_c *MockErrorHandler_Handle_Call) RunAndReturn(run func(error) error) *Moc
 	return _c
 }
 
+// Include provides a mock function with given fields: groups
+func (_m *MockErrorHandler) Include(groups ...*arpc.ErrorCaseGroup) arpc.ErrorHandler {
+	_va := make([]interface{}, len(groups))
+	for _i := range groups {
+		_va[_i] = groups[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Include")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(...*arpc.ErrorCaseGroup) arpc.ErrorHandler); ok {
+		r0 = rf(groups...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Include_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Include'
+type MockErrorHandler_Include_Call struct {
+	*mock.Call
+}
+
+// Include is a helper method to define mock.On call
+//   - groups ...*arpc.ErrorCaseGroup
+func (_e *MockErrorHandler_Expecter) Include(groups ...interface{}) *MockErrorHandler_Include_Call {
+	return &MockErrorHandler_Include_Call{Call: _e.mock.On("Include",
+		append([]interface{}{}, groups...)...)}
+}
+
+func (_c *MockErrorHandler_Include_Call) Run(run func(groups ...*arpc.ErrorCaseGroup)) *MockErrorHandler_Include_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]*arpc.ErrorCaseGroup, len(args)-0)
+		for i, a := range args[0:] {
+			if a != nil {
+				variadicArgs[i] = a.(*arpc.ErrorCaseGroup)
+			}
+		}
+		run(variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Include_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Include_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Include_Call) RunAndReturn(run func(...*arpc.ErrorCaseGroup) arpc.ErrorHandler) *MockErrorHandler_Include_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Instrument provides a mock function with given fields: config
+func (_m *MockErrorHandler) Instrument(config *arpc.ErrorInstrumentation) arpc.ErrorHandler {
+	ret := _m.Called(config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Instrument")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(*arpc.ErrorInstrumentation) arpc.ErrorHandler); ok {
+		r0 = rf(config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Instrument_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Instrument'
+type MockErrorHandler_Instrument_Call struct {
+	*mock.Call
+}
+
+// Instrument is a helper method to define mock.On call
+//   - config *arpc.ErrorInstrumentation
+func (_e *MockErrorHandler_Expecter) Instrument(config interface{}) *MockErrorHandler_Instrument_Call {
+	return &MockErrorHandler_Instrument_Call{Call: _e.mock.On("Instrument", config)}
+}
+
+func (_c *MockErrorHandler_Instrument_Call) Run(run func(config *arpc.ErrorInstrumentation)) *MockErrorHandler_Instrument_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*arpc.ErrorInstrumentation))
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Instrument_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Instrument_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Instrument_Call) RunAndReturn(run func(*arpc.ErrorInstrumentation) arpc.ErrorHandler) *MockErrorHandler_Instrument_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Is provides a mock function with given fields: target, code
 func (_m *MockErrorHandler) Is(target error, code codes.Code) arpc.ErrorHandler {
 	ret := _m.Called(target, code)
@@ -277,6 +606,70 @@ func (_c *MockErrorHandler_Is_Call) RunAndReturn(run func(error, codes.Code) arp
 	return _c
 }
 
+// IsD provides a mock function with given fields: target, code, details
+func (_m *MockErrorHandler) IsD(target error, code codes.Code, details ...arpc.ErrorDetail) arpc.ErrorHandler {
+	_va := make([]interface{}, len(details))
+	for _i := range details {
+		_va[_i] = details[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, target, code)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsD")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(error, codes.Code, ...arpc.ErrorDetail) arpc.ErrorHandler); ok {
+		r0 = rf(target, code, details...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_IsD_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsD'
+type MockErrorHandler_IsD_Call struct {
+	*mock.Call
+}
+
+// IsD is a helper method to define mock.On call
+//   - target error
+//   - code codes.Code
+//   - details ...arpc.ErrorDetail
+func (_e *MockErrorHandler_Expecter) IsD(target interface{}, code interface{}, details ...interface{}) *MockErrorHandler_IsD_Call {
+	return &MockErrorHandler_IsD_Call{Call: _e.mock.On("IsD",
+		append([]interface{}{target, code}, details...)...)}
+}
+
+func (_c *MockErrorHandler_IsD_Call) Run(run func(target error, code codes.Code, details ...arpc.ErrorDetail)) *MockErrorHandler_IsD_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]arpc.ErrorDetail, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(arpc.ErrorDetail)
+			}
+		}
+		run(args[0].(error), args[1].(codes.Code), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_IsD_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_IsD_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_IsD_Call) RunAndReturn(run func(error, codes.Code, ...arpc.ErrorDetail) arpc.ErrorHandler) *MockErrorHandler_IsD_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // IsW provides a mock function with given fields: target, code, wrap
 func (_m *MockErrorHandler) IsW(target error, code codes.Code, wrap error) arpc.ErrorHandler {
 	ret := _m.Called(target, code, wrap)
@@ -388,6 +781,197 @@ func (_c *MockErrorHandler_IsWF_Call) RunAndReturn(run func(error, codes.Code, s
 	return _c
 }
 
+// Mappings provides a mock function with no fields
+func (_m *MockErrorHandler) Mappings() []arpcmessages.ErrorMapping {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Mappings")
+	}
+
+	var r0 []arpcmessages.ErrorMapping
+	if rf, ok := ret.Get(0).(func() []arpcmessages.ErrorMapping); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]arpcmessages.ErrorMapping)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Mappings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Mappings'
+type MockErrorHandler_Mappings_Call struct {
+	*mock.Call
+}
+
+// Mappings is a helper method to define mock.On call
+func (_e *MockErrorHandler_Expecter) Mappings() *MockErrorHandler_Mappings_Call {
+	return &MockErrorHandler_Mappings_Call{Call: _e.mock.On("Mappings")}
+}
+
+func (_c *MockErrorHandler_Mappings_Call) Run(run func()) *MockErrorHandler_Mappings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Mappings_Call) Return(_a0 []arpcmessages.ErrorMapping) *MockErrorHandler_Mappings_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Mappings_Call) RunAndReturn(run func() []arpcmessages.ErrorMapping) *MockErrorHandler_Mappings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Named provides a mock function with given fields: name
+func (_m *MockErrorHandler) Named(name string) arpc.ErrorHandler {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Named")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(string) arpc.ErrorHandler); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Named_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Named'
+type MockErrorHandler_Named_Call struct {
+	*mock.Call
+}
+
+// Named is a helper method to define mock.On call
+//   - name string
+func (_e *MockErrorHandler_Expecter) Named(name interface{}) *MockErrorHandler_Named_Call {
+	return &MockErrorHandler_Named_Call{Call: _e.mock.On("Named", name)}
+}
+
+func (_c *MockErrorHandler_Named_Call) Run(run func(name string)) *MockErrorHandler_Named_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Named_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Named_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Named_Call) RunAndReturn(run func(string) arpc.ErrorHandler) *MockErrorHandler_Named_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Passthrough provides a mock function with given fields: policy
+func (_m *MockErrorHandler) Passthrough(policy arpc.StatusPolicy) arpc.ErrorHandler {
+	ret := _m.Called(policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Passthrough")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(arpc.StatusPolicy) arpc.ErrorHandler); ok {
+		r0 = rf(policy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Passthrough_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Passthrough'
+type MockErrorHandler_Passthrough_Call struct {
+	*mock.Call
+}
+
+// Passthrough is a helper method to define mock.On call
+//   - policy arpc.StatusPolicy
+func (_e *MockErrorHandler_Expecter) Passthrough(policy interface{}) *MockErrorHandler_Passthrough_Call {
+	return &MockErrorHandler_Passthrough_Call{Call: _e.mock.On("Passthrough", policy)}
+}
+
+func (_c *MockErrorHandler_Passthrough_Call) Run(run func(policy arpc.StatusPolicy)) *MockErrorHandler_Passthrough_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(arpc.StatusPolicy))
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Passthrough_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Passthrough_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Passthrough_Call) RunAndReturn(run func(arpc.StatusPolicy) arpc.ErrorHandler) *MockErrorHandler_Passthrough_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Sanitize provides a mock function with given fields: config
+func (_m *MockErrorHandler) Sanitize(config *arpc.SanitizeConfig) arpc.ErrorHandler {
+	ret := _m.Called(config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sanitize")
+	}
+
+	var r0 arpc.ErrorHandler
+	if rf, ok := ret.Get(0).(func(*arpc.SanitizeConfig) arpc.ErrorHandler); ok {
+		r0 = rf(config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(arpc.ErrorHandler)
+		}
+	}
+
+	return r0
+}
+
+// MockErrorHandler_Sanitize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sanitize'
+type MockErrorHandler_Sanitize_Call struct {
+	*mock.Call
+}
+
+// Sanitize is a helper method to define mock.On call
+//   - config *arpc.SanitizeConfig
+func (_e *MockErrorHandler_Expecter) Sanitize(config interface{}) *MockErrorHandler_Sanitize_Call {
+	return &MockErrorHandler_Sanitize_Call{Call: _e.mock.On("Sanitize", config)}
+}
+
+func (_c *MockErrorHandler_Sanitize_Call) Run(run func(config *arpc.SanitizeConfig)) *MockErrorHandler_Sanitize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*arpc.SanitizeConfig))
+	})
+	return _c
+}
+
+func (_c *MockErrorHandler_Sanitize_Call) Return(_a0 arpc.ErrorHandler) *MockErrorHandler_Sanitize_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockErrorHandler_Sanitize_Call) RunAndReturn(run func(*arpc.SanitizeConfig) arpc.ErrorHandler) *MockErrorHandler_Sanitize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Test provides a mock function with given fields: caseFn
 func (_m *MockErrorHandler) Test(caseFn func(error) (error, bool)) arpc.ErrorHandler {
 	ret := _m.Called(caseFn)
@@ -0,0 +1,96 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package arpcmocks
+
+import (
+	context "context"
+	crypto "crypto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockKeySource is an autogenerated mock type for the KeySource type
+type MockKeySource struct {
+	mock.Mock
+}
+
+type MockKeySource_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockKeySource) EXPECT() *MockKeySource_Expecter {
+	return &MockKeySource_Expecter{mock: &_m.Mock}
+}
+
+// PublicKey provides a mock function with given fields: ctx, keyID
+func (_m *MockKeySource) PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	ret := _m.Called(ctx, keyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublicKey")
+	}
+
+	var r0 crypto.PublicKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (crypto.PublicKey, error)); ok {
+		return rf(ctx, keyID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) crypto.PublicKey); ok {
+		r0 = rf(ctx, keyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(crypto.PublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockKeySource_PublicKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PublicKey'
+type MockKeySource_PublicKey_Call struct {
+	*mock.Call
+}
+
+// PublicKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyID string
+func (_e *MockKeySource_Expecter) PublicKey(ctx interface{}, keyID interface{}) *MockKeySource_PublicKey_Call {
+	return &MockKeySource_PublicKey_Call{Call: _e.mock.On("PublicKey", ctx, keyID)}
+}
+
+func (_c *MockKeySource_PublicKey_Call) Run(run func(ctx context.Context, keyID string)) *MockKeySource_PublicKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockKeySource_PublicKey_Call) Return(_a0 crypto.PublicKey, _a1 error) *MockKeySource_PublicKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockKeySource_PublicKey_Call) RunAndReturn(run func(context.Context, string) (crypto.PublicKey, error)) *MockKeySource_PublicKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockKeySource creates a new instance of MockKeySource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockKeySource(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockKeySource {
+	mock := &MockKeySource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
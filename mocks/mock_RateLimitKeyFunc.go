@@ -0,0 +1,83 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package arpcmocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockRateLimitKeyFunc is an autogenerated mock type for the RateLimitKeyFunc type
+type MockRateLimitKeyFunc struct {
+	mock.Mock
+}
+
+type MockRateLimitKeyFunc_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRateLimitKeyFunc) EXPECT() *MockRateLimitKeyFunc_Expecter {
+	return &MockRateLimitKeyFunc_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function with given fields: ctx, fullMethod
+func (_m *MockRateLimitKeyFunc) Execute(ctx context.Context, fullMethod string) string {
+	ret := _m.Called(ctx, fullMethod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Execute")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, fullMethod)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockRateLimitKeyFunc_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockRateLimitKeyFunc_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fullMethod string
+func (_e *MockRateLimitKeyFunc_Expecter) Execute(ctx interface{}, fullMethod interface{}) *MockRateLimitKeyFunc_Execute_Call {
+	return &MockRateLimitKeyFunc_Execute_Call{Call: _e.mock.On("Execute", ctx, fullMethod)}
+}
+
+func (_c *MockRateLimitKeyFunc_Execute_Call) Run(run func(ctx context.Context, fullMethod string)) *MockRateLimitKeyFunc_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRateLimitKeyFunc_Execute_Call) Return(_a0 string) *MockRateLimitKeyFunc_Execute_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRateLimitKeyFunc_Execute_Call) RunAndReturn(run func(context.Context, string) string) *MockRateLimitKeyFunc_Execute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRateLimitKeyFunc creates a new instance of MockRateLimitKeyFunc. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRateLimitKeyFunc(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRateLimitKeyFunc {
+	mock := &MockRateLimitKeyFunc{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
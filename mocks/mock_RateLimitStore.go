@@ -0,0 +1,151 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package arpcmocks
+
+import (
+	context "context"
+	time "time"
+
+	arpc "github.com/a-novel-kit/arpc"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockRateLimitStore is an autogenerated mock type for the RateLimitStore type
+type MockRateLimitStore struct {
+	mock.Mock
+}
+
+type MockRateLimitStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRateLimitStore) EXPECT() *MockRateLimitStore_Expecter {
+	return &MockRateLimitStore_Expecter{mock: &_m.Mock}
+}
+
+// Refund provides a mock function with given fields: ctx, key, limit
+func (_m *MockRateLimitStore) Refund(ctx context.Context, key string, limit arpc.RateLimit) error {
+	ret := _m.Called(ctx, key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refund")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, arpc.RateLimit) error); ok {
+		r0 = rf(ctx, key, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRateLimitStore_Refund_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refund'
+type MockRateLimitStore_Refund_Call struct {
+	*mock.Call
+}
+
+// Refund is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - limit arpc.RateLimit
+func (_e *MockRateLimitStore_Expecter) Refund(ctx interface{}, key interface{}, limit interface{}) *MockRateLimitStore_Refund_Call {
+	return &MockRateLimitStore_Refund_Call{Call: _e.mock.On("Refund", ctx, key, limit)}
+}
+
+func (_c *MockRateLimitStore_Refund_Call) Run(run func(ctx context.Context, key string, limit arpc.RateLimit)) *MockRateLimitStore_Refund_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(arpc.RateLimit))
+	})
+	return _c
+}
+
+func (_c *MockRateLimitStore_Refund_Call) Return(_a0 error) *MockRateLimitStore_Refund_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRateLimitStore_Refund_Call) RunAndReturn(run func(context.Context, string, arpc.RateLimit) error) *MockRateLimitStore_Refund_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Take provides a mock function with given fields: ctx, key, limit
+func (_m *MockRateLimitStore) Take(ctx context.Context, key string, limit arpc.RateLimit) (bool, time.Duration, error) {
+	ret := _m.Called(ctx, key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Take")
+	}
+
+	var r0 bool
+	var r1 time.Duration
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, arpc.RateLimit) (bool, time.Duration, error)); ok {
+		return rf(ctx, key, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, arpc.RateLimit) bool); ok {
+		r0 = rf(ctx, key, limit)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, arpc.RateLimit) time.Duration); ok {
+		r1 = rf(ctx, key, limit)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, arpc.RateLimit) error); ok {
+		r2 = rf(ctx, key, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockRateLimitStore_Take_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Take'
+type MockRateLimitStore_Take_Call struct {
+	*mock.Call
+}
+
+// Take is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - limit arpc.RateLimit
+func (_e *MockRateLimitStore_Expecter) Take(ctx interface{}, key interface{}, limit interface{}) *MockRateLimitStore_Take_Call {
+	return &MockRateLimitStore_Take_Call{Call: _e.mock.On("Take", ctx, key, limit)}
+}
+
+func (_c *MockRateLimitStore_Take_Call) Run(run func(ctx context.Context, key string, limit arpc.RateLimit)) *MockRateLimitStore_Take_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(arpc.RateLimit))
+	})
+	return _c
+}
+
+func (_c *MockRateLimitStore_Take_Call) Return(_a0 bool, _a1 time.Duration, _a2 error) *MockRateLimitStore_Take_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockRateLimitStore_Take_Call) RunAndReturn(run func(context.Context, string, arpc.RateLimit) (bool, time.Duration, error)) *MockRateLimitStore_Take_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRateLimitStore creates a new instance of MockRateLimitStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRateLimitStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRateLimitStore {
+	mock := &MockRateLimitStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
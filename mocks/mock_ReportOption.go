@@ -0,0 +1,68 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package arpcmocks
+
+import (
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockReportOption is an autogenerated mock type for the ReportOption type
+type MockReportOption struct {
+	mock.Mock
+}
+
+type MockReportOption_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockReportOption) EXPECT() *MockReportOption_Expecter {
+	return &MockReportOption_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function with given fields: details
+func (_m *MockReportOption) Execute(details *arpcmessages.ReportDetails) {
+	_m.Called(details)
+}
+
+// MockReportOption_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockReportOption_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - details *arpcmessages.ReportDetails
+func (_e *MockReportOption_Expecter) Execute(details interface{}) *MockReportOption_Execute_Call {
+	return &MockReportOption_Execute_Call{Call: _e.mock.On("Execute", details)}
+}
+
+func (_c *MockReportOption_Execute_Call) Run(run func(details *arpcmessages.ReportDetails)) *MockReportOption_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*arpcmessages.ReportDetails))
+	})
+	return _c
+}
+
+func (_c *MockReportOption_Execute_Call) Return() *MockReportOption_Execute_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockReportOption_Execute_Call) RunAndReturn(run func(*arpcmessages.ReportDetails)) *MockReportOption_Execute_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewMockReportOption creates a new instance of MockReportOption. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockReportOption(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockReportOption {
+	mock := &MockReportOption{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,180 @@
+package arpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var ErrInvalidClientCA = errors.New("invalid client CA certificate")
+
+// ServerTLSConfig creates a TLS configuration for servers that require mutual authentication. Clients must present
+// a certificate signed by one of the client CAs.
+//
+//	tlsConfig, err := arpc.ServerTLSConfig(certPEM, keyPEM, clientCAPEM)
+//	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Port: 8080, TLS: tlsConfig})
+func ServerTLSConfig(certPEM, keyPEM []byte, clientCAs ...[]byte) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("load server key pair: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	for _, clientCA := range clientCAs {
+		if !pool.AppendCertsFromPEM(clientCA) {
+			return nil, ErrInvalidClientCA
+		}
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// PeerIdentity is the identity of a client, as presented by its verified certificate.
+type PeerIdentity struct {
+	// Subject is the distinguished name of the certificate, e.g. "CN=client,O=org".
+	Subject    string
+	CommonName string
+
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+
+	// SPIFFEID is the first URI SAN using the spiffe scheme, if any.
+	SPIFFEID string
+
+	Certificate *x509.Certificate
+}
+
+// Principal returns the most specific name of the peer: its SPIFFE ID when available, its common name otherwise.
+func (identity *PeerIdentity) Principal() string {
+	if identity.SPIFFEID != "" {
+		return identity.SPIFFEID
+	}
+
+	return identity.CommonName
+}
+
+// Matches reports whether the given name designates the peer. The name is compared to the subject, common name,
+// SPIFFE ID and every SAN of the certificate.
+func (identity *PeerIdentity) Matches(name string) bool {
+	return name == identity.Subject ||
+		name == identity.CommonName ||
+		(identity.SPIFFEID != "" && name == identity.SPIFFEID) ||
+		slices.Contains(identity.DNSNames, name) ||
+		slices.Contains(identity.EmailAddresses, name) ||
+		slices.Contains(identity.URIs, name)
+}
+
+func newPeerIdentity(cert *x509.Certificate) *PeerIdentity {
+	identity := &PeerIdentity{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		Certificate:    cert,
+	}
+
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+
+		if identity.SPIFFEID == "" && strings.EqualFold(uri.Scheme, "spiffe") {
+			identity.SPIFFEID = uri.String()
+		}
+	}
+
+	return identity
+}
+
+// PeerIdentityFromContext returns the identity of the caller, when it presented a certificate that was verified by
+// the server.
+func PeerIdentityFromContext(ctx context.Context) (*PeerIdentity, bool) {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tlsInfo, ok := pr.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, false
+	}
+
+	// Only trust certificates that went through verification.
+	if len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return newPeerIdentity(tlsInfo.State.VerifiedChains[0][0]), true
+}
+
+// PeerAllowList maps method patterns to the peer identities allowed to call them. Patterns follow the syntax of
+// path.Match. Methods that match no pattern are not restricted. When a method matches many patterns, the peer must be
+// allowed by every one of them, so specific patterns can only narrow broader ones.
+//
+//	arpc.PeerAllowList{
+//		// Only the admin can delete users, although the frontend can call the rest of the service.
+//		"/users.Users/Delete": {"spiffe://example.org/admin"},
+//		"/users.Users/*":      {"spiffe://example.org/admin", "spiffe://example.org/frontend"},
+//	}
+type PeerAllowList map[string][]string
+
+func (allowList PeerAllowList) authorize(ctx context.Context, fullMethod string) error {
+	var identity *PeerIdentity
+
+	for pattern, allowed := range allowList {
+		if !matchPattern(pattern, fullMethod) {
+			continue
+		}
+
+		if identity == nil {
+			var ok bool
+			if identity, ok = PeerIdentityFromContext(ctx); !ok {
+				return status.Error(codes.Unauthenticated, "missing peer certificate") //nolint:wrapcheck
+			}
+		}
+
+		if !slices.ContainsFunc(allowed, identity.Matches) {
+			return status.Errorf(codes.PermissionDenied, "peer %s is not allowed to call %s", identity.Subject, fullMethod)
+		}
+	}
+
+	return nil
+}
+
+// UnaryPeerAuthorizationInterceptor restricts unary calls to the peers listed in the allow list.
+func UnaryPeerAuthorizationInterceptor(allowList PeerAllowList) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := allowList.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamPeerAuthorizationInterceptor restricts streams to the peers listed in the allow list.
+func StreamPeerAuthorizationInterceptor(allowList PeerAllowList) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowList.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,272 @@
+package arpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+	x509mocks "github.com/a-novel-kit/arpc/mocks/x509/x509"
+)
+
+// Create a client certificate with a SPIFFE ID, signed by the mocked client CA.
+func spiffeClientCert(t *testing.T, spiffeID string) tls.Certificate {
+	t.Helper()
+
+	caCert, err := tls.X509KeyPair(x509mocks.ClientCACertPEM, x509mocks.ClientCAKeyPEM)
+	require.NoError(t, err)
+
+	caLeaf, err := x509.ParseCertificate(caCert.Certificate[0])
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	uri, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "workload"},
+		URIs:         []*url.URL{uri},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caLeaf, &key.PublicKey, caCert.PrivateKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func TestPeerIdentity(t *testing.T) {
+	tlsConfig, err := arpc.ServerTLSConfig(x509mocks.Server1CertPEM, x509mocks.Server1KeyPEM, x509mocks.ClientCACertPEM)
+	require.NoError(t, err)
+
+	allowList := arpc.PeerAllowList{
+		"/grpc.testing.TestService/EmptyCall": {"test-client1", "spiffe://example.org/workload"},
+	}
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{
+		Ephemeral: true,
+		Host:      "127.0.0.1",
+		TLS:       tlsConfig,
+		Options: []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(arpc.UnaryPeerAuthorizationInterceptor(allowList)),
+			grpc.ChainStreamInterceptor(arpc.StreamPeerAuthorizationInterceptor(allowList)),
+		},
+	})
+	require.NoError(t, err)
+	defer arpc.CloseServer(listener, server)
+
+	identities := make(chan *arpc.PeerIdentity, 1)
+
+	testgrpc.RegisterTestServiceServer(server, &arpcmocks.StubServer{
+		EmptyCallF: func(ctx context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			identity, ok := arpc.PeerIdentityFromContext(ctx)
+			if !ok {
+				return nil, status.Error(codes.DataLoss, "missing identity")
+			}
+
+			identities <- identity
+
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	})
+
+	go func() {
+		require.NoError(t, server.Serve(listener))
+	}()
+
+	serverCAs := x509.NewCertPool()
+	require.True(t, serverCAs.AppendCertsFromPEM(x509mocks.ServerCACertPEM))
+
+	client1, err := tls.X509KeyPair(x509mocks.Client1CertPEM, x509mocks.Client1KeyPEM)
+	require.NoError(t, err)
+
+	client2, err := tls.X509KeyPair(x509mocks.Client2CertPEM, x509mocks.Client2KeyPEM)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+
+		cert tls.Certificate
+
+		expectCode     codes.Code
+		expectIdentity func(t *testing.T, identity *arpc.PeerIdentity)
+	}{
+		{
+			name: "Client1",
+
+			cert: client1,
+
+			expectCode: codes.OK,
+			expectIdentity: func(t *testing.T, identity *arpc.PeerIdentity) {
+				t.Helper()
+
+				require.Equal(t, "test-client1", identity.CommonName)
+				require.Equal(t, "CN=test-client1,O=gRPC,L=SVL,ST=CA,C=US", identity.Subject)
+				require.Equal(t, []string{"127.0.0.1:8080"}, identity.DNSNames)
+				require.Empty(t, identity.SPIFFEID)
+				require.Equal(t, "test-client1", identity.Principal())
+			},
+		},
+		{
+			name: "SPIFFE",
+
+			cert: spiffeClientCert(t, "spiffe://example.org/workload"),
+
+			expectCode: codes.OK,
+			expectIdentity: func(t *testing.T, identity *arpc.PeerIdentity) {
+				t.Helper()
+
+				require.Equal(t, "spiffe://example.org/workload", identity.SPIFFEID)
+				require.Equal(t, []string{"spiffe://example.org/workload"}, identity.URIs)
+				require.Equal(t, "spiffe://example.org/workload", identity.Principal())
+			},
+		},
+		{
+			name: "NotAllowed",
+
+			cert: client2,
+
+			expectCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			transport := credentials.NewTLS(&tls.Config{
+				Certificates: []tls.Certificate{testCase.cert},
+				RootCAs:      serverCAs,
+				ServerName:   "127.0.0.1:8080",
+				MinVersion:   tls.VersionTLS12,
+			})
+
+			conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(transport))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			client := testgrpc.NewTestServiceClient(conn)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = client.EmptyCall(ctx, new(testgrpc.Empty))
+			require.Equal(t, testCase.expectCode, status.Code(err), err)
+
+			if testCase.expectIdentity != nil {
+				testCase.expectIdentity(t, <-identities)
+			}
+
+			// Unlisted methods are not restricted.
+			_, err = client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPeerAllowListOverlap(t *testing.T) {
+	allowList := arpc.PeerAllowList{
+		"/users.Users/Delete": {"spiffe://example.org/admin"},
+		"/users.Users/*":      {"spiffe://example.org/admin", "spiffe://example.org/frontend"},
+	}
+
+	interceptor := arpc.UnaryPeerAuthorizationInterceptor(allowList)
+
+	handler := func(_ context.Context, _ interface{}) (interface{}, error) {
+		return new(testgrpc.Empty), nil
+	}
+
+	peerContext := func(t *testing.T, spiffeID string) context.Context {
+		t.Helper()
+
+		leaf, err := x509.ParseCertificate(spiffeClientCert(t, spiffeID).Certificate[0])
+		require.NoError(t, err)
+
+		return peer.NewContext(context.Background(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}},
+		})
+	}
+
+	testCases := []struct {
+		name string
+
+		spiffeID string
+		method   string
+
+		expectCode codes.Code
+	}{
+		{
+			name: "FrontendList",
+
+			spiffeID: "spiffe://example.org/frontend",
+			method:   "/users.Users/List",
+
+			expectCode: codes.OK,
+		},
+		{
+			name: "FrontendDelete",
+
+			spiffeID: "spiffe://example.org/frontend",
+			method:   "/users.Users/Delete",
+
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			name: "AdminDelete",
+
+			spiffeID: "spiffe://example.org/admin",
+			method:   "/users.Users/Delete",
+
+			expectCode: codes.OK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Patterns are iterated in random order, so run each case a few times.
+			for range 10 {
+				_, err := interceptor(
+					peerContext(t, testCase.spiffeID),
+					nil,
+					&grpc.UnaryServerInfo{FullMethod: testCase.method},
+					handler,
+				)
+				require.Equal(t, testCase.expectCode, status.Code(err), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package arpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/a-novel-kit/quicklog"
+	"github.com/a-novel-kit/quicklog/messages"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrInvalidPolicy    = errors.New("invalid policy")
+)
+
+// PrincipalFromContext returns the name of the caller. The email of a verified ID token is preferred, if the email
+// itself is verified, then its subject, then the principal of a verified peer certificate.
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	if claims, ok := IDTokenClaimsFromContext(ctx); ok {
+		if claims.Email != "" && claims.EmailVerified {
+			return claims.Email, true
+		}
+
+		if claims.Subject != "" {
+			return claims.Subject, true
+		}
+	}
+
+	if identity, ok := PeerIdentityFromContext(ctx); ok {
+		if principal := identity.Principal(); principal != "" {
+			return principal, true
+		}
+	}
+
+	return "", false
+}
+
+type PolicyEffect string
+
+const (
+	PolicyAllow PolicyEffect = "allow"
+	PolicyDeny  PolicyEffect = "deny"
+)
+
+// PolicyRule grants or denies access to a set of methods. A rule applies to a call when every one of its conditions
+// matches. Empty conditions always match.
+type PolicyRule struct {
+	// Name identifies the rule in audit logs.
+	Name   string       `json:"name"`
+	Effect PolicyEffect `json:"effect"`
+	// Methods lists full method patterns, following the syntax of path.Match.
+	Methods []string `json:"methods"`
+	// Principals lists caller patterns, as returned by PrincipalFromContext.
+	Principals []string `json:"principals,omitempty"`
+	// Metadata requires some incoming metadata keys to have a value matching the given pattern.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (rule *PolicyRule) matches(fullMethod, principal string, md metadata.MD) bool {
+	if !matchAnyPattern(rule.Methods, fullMethod) {
+		return false
+	}
+
+	if len(rule.Principals) > 0 && (principal == "" || !matchAnyPattern(rule.Principals, principal)) {
+		return false
+	}
+
+	for key, pattern := range rule.Metadata {
+		if !matchAnyValue(pattern, md.Get(key)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchAnyValue(pattern string, values []string) bool {
+	for _, value := range values {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policy is a set of authorization rules. Deny rules always take precedence over allow rules. Calls that match no
+// rule are allowed, unless DenyByDefault is set.
+//
+//	{
+//	  "denyByDefault": true,
+//	  "rules": [
+//	    {
+//	      "name": "admins",
+//	      "effect": "allow",
+//	      "methods": ["/users.Users/*"],
+//	      "principals": ["admin@my-project.iam.gserviceaccount.com"]
+//	    }
+//	  ]
+//	}
+type Policy struct {
+	DenyByDefault bool         `json:"denyByDefault"`
+	Rules         []PolicyRule `json:"rules"`
+}
+
+// Evaluate returns whether a call is allowed, along with the name of the rule that took the decision. An empty
+// rule name means the default policy was applied.
+func (policy *Policy) Evaluate(fullMethod, principal string, md metadata.MD) (bool, string) {
+	var allowRule *PolicyRule
+
+	for i, rule := range policy.Rules {
+		if !rule.matches(fullMethod, principal, md) {
+			continue
+		}
+
+		if rule.Effect == PolicyDeny {
+			return false, rule.Name
+		}
+
+		if allowRule == nil {
+			allowRule = &policy.Rules[i]
+		}
+	}
+
+	if allowRule != nil {
+		return true, allowRule.Name
+	}
+
+	return !policy.DenyByDefault, ""
+}
+
+func (policy *Policy) validate() error {
+	for i, rule := range policy.Rules {
+		if rule.Effect != PolicyAllow && rule.Effect != PolicyDeny {
+			return fmt.Errorf("%w: rule %d (%s): unknown effect %q", ErrInvalidPolicy, i, rule.Name, rule.Effect)
+		}
+
+		if len(rule.Methods) == 0 {
+			return fmt.Errorf("%w: rule %d (%s): no method", ErrInvalidPolicy, i, rule.Name)
+		}
+	}
+
+	return nil
+}
+
+// LoadPolicy reads a policy from a JSON file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file: %w", err)
+	}
+
+	policy := new(Policy)
+	if err = json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPolicy, err)
+	}
+
+	if err = policy.validate(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// PolicyConfig configures a PolicyAuthorizer.
+type PolicyConfig struct {
+	// Path of a JSON policy file. When set, the file is watched and reloaded on change.
+	Path string
+	// ReloadInterval is the delay between two checks of the policy file. Defaults to 10 seconds.
+	ReloadInterval time.Duration
+	// Policy is used when no Path is provided.
+	Policy *Policy
+
+	// Logger is optional. When set, it receives an audit message for every decision, and errors that happen while
+	// reloading the policy.
+	Logger quicklog.Logger
+	// ErrorHandler converts denials, wrapping ErrPermissionDenied, into status errors. Defaults to
+	// HandleError(codes.PermissionDenied).
+	ErrorHandler ErrorHandler
+}
+
+// PolicyAuthorizer enforces a Policy on every call. It must be closed to stop watching the policy file.
+type PolicyAuthorizer struct {
+	config *PolicyConfig
+	policy atomic.Pointer[Policy]
+
+	modTime time.Time
+	done    chan struct{}
+	once    sync.Once
+
+	mu sync.Mutex
+}
+
+func (authorizer *PolicyAuthorizer) reload(force bool) error {
+	authorizer.mu.Lock()
+	defer authorizer.mu.Unlock()
+
+	info, err := os.Stat(authorizer.config.Path)
+	if err != nil {
+		return fmt.Errorf("stat policy file: %w", err)
+	}
+
+	if !force && info.ModTime().Equal(authorizer.modTime) {
+		return nil
+	}
+
+	policy, err := LoadPolicy(authorizer.config.Path)
+	if err != nil {
+		return err
+	}
+
+	authorizer.modTime = info.ModTime()
+	authorizer.policy.Store(policy)
+
+	return nil
+}
+
+// Reload reads the policy file again. On failure, the current policy is kept.
+func (authorizer *PolicyAuthorizer) Reload() error {
+	if authorizer.config.Path == "" {
+		return nil
+	}
+
+	return authorizer.reload(true)
+}
+
+func (authorizer *PolicyAuthorizer) watch() {
+	interval := authorizer.config.ReloadInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-authorizer.done:
+			return
+		case <-ticker.C:
+			if err := authorizer.reload(false); err != nil && authorizer.config.Logger != nil {
+				authorizer.config.Logger.Log(quicklog.LevelError, messages.NewError(err, "reload authorization policy"))
+			}
+		}
+	}
+}
+
+// Close stops watching the policy file.
+func (authorizer *PolicyAuthorizer) Close() {
+	authorizer.once.Do(func() {
+		close(authorizer.done)
+	})
+}
+
+// Authorize returns a status error if the policy denies the call.
+func (authorizer *PolicyAuthorizer) Authorize(ctx context.Context, fullMethod string) error {
+	principal, _ := PrincipalFromContext(ctx)
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	allowed, rule := authorizer.policy.Load().Evaluate(fullMethod, principal, md)
+
+	if authorizer.config.Logger != nil {
+		level := quicklog.LevelInfo
+		if !allowed {
+			level = quicklog.LevelWarning
+		}
+
+		authorizer.config.Logger.Log(level, arpcmessages.NewAudit(fullMethod, principal, rule, allowed))
+	}
+
+	if allowed {
+		return nil
+	}
+
+	return authorizer.config.ErrorHandler.Handle(
+		fmt.Errorf("%w: %s cannot call %s", ErrPermissionDenied, principal, fullMethod),
+	)
+}
+
+// NewPolicyAuthorizer creates a new authorizer, enforcing the configured policy.
+func NewPolicyAuthorizer(config *PolicyConfig) (*PolicyAuthorizer, error) {
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = HandleError(codes.PermissionDenied)
+	}
+
+	authorizer := &PolicyAuthorizer{config: config, done: make(chan struct{})}
+
+	if config.Path == "" {
+		policy := config.Policy
+		if policy == nil {
+			policy = new(Policy)
+		}
+
+		if err := policy.validate(); err != nil {
+			return nil, err
+		}
+
+		authorizer.policy.Store(policy)
+
+		return authorizer, nil
+	}
+
+	if err := authorizer.Reload(); err != nil {
+		return nil, err
+	}
+
+	go authorizer.watch()
+
+	return authorizer, nil
+}
+
+// UnaryPolicyInterceptor enforces the policy of an authorizer on unary calls.
+func UnaryPolicyInterceptor(authorizer *PolicyAuthorizer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := authorizer.Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamPolicyInterceptor enforces the policy of an authorizer on streams.
+func StreamPolicyInterceptor(authorizer *PolicyAuthorizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorizer.Authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,230 @@
+package arpc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := &arpc.Policy{
+		Rules: []arpc.PolicyRule{
+			{
+				Name:       "admins",
+				Effect:     arpc.PolicyAllow,
+				Methods:    []string{"/users.Users/*"},
+				Principals: []string{"*@admin.iam.gserviceaccount.com"},
+			},
+			{
+				Name:    "no-delete-from-staging",
+				Effect:  arpc.PolicyDeny,
+				Methods: []string{"/users.Users/Delete"},
+				Metadata: map[string]string{
+					"x-environment": "staging",
+				},
+			},
+			{
+				Name:    "public",
+				Effect:  arpc.PolicyAllow,
+				Methods: []string{"/users.Users/Get"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name string
+
+		denyByDefault bool
+		method        string
+		principal     string
+		md            metadata.MD
+
+		expectAllowed bool
+		expectRule    string
+	}{
+		{
+			name: "AllowedPrincipal",
+
+			method:    "/users.Users/Delete",
+			principal: "bob@admin.iam.gserviceaccount.com",
+
+			expectAllowed: true,
+			expectRule:    "admins",
+		},
+		{
+			name: "DenyTakesPrecedence",
+
+			method:    "/users.Users/Delete",
+			principal: "bob@admin.iam.gserviceaccount.com",
+			md:        metadata.Pairs("x-environment", "staging"),
+
+			expectAllowed: false,
+			expectRule:    "no-delete-from-staging",
+		},
+		{
+			name: "AnyPrincipal",
+
+			method: "/users.Users/Get",
+
+			expectAllowed: true,
+			expectRule:    "public",
+		},
+		{
+			name: "Default",
+
+			method:    "/users.Users/Delete",
+			principal: "alice@other.iam.gserviceaccount.com",
+
+			expectAllowed: true,
+		},
+		{
+			name: "DenyByDefault",
+
+			denyByDefault: true,
+			method:        "/users.Users/Delete",
+			principal:     "alice@other.iam.gserviceaccount.com",
+
+			expectAllowed: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			policy.DenyByDefault = testCase.denyByDefault
+
+			allowed, rule := policy.Evaluate(testCase.method, testCase.principal, testCase.md)
+			require.Equal(t, testCase.expectAllowed, allowed)
+			require.Equal(t, testCase.expectRule, rule)
+		})
+	}
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"effect": "maybe", "methods": ["*"]}]}`), 0o600))
+
+	_, err := arpc.LoadPolicy(path)
+	require.ErrorIs(t, err, arpc.ErrInvalidPolicy)
+}
+
+func TestPolicyInterceptors(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	writePolicy := func(path string, policy *arpc.Policy) {
+		data, err := json.Marshal(policy)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+	}
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	writePolicy(path, &arpc.Policy{
+		DenyByDefault: true,
+		Rules: []arpc.PolicyRule{
+			{
+				Name:       "callers",
+				Effect:     arpc.PolicyAllow,
+				Methods:    []string{"/grpc.testing.TestService/EmptyCall"},
+				Principals: []string{"caller@project.iam.gserviceaccount.com"},
+			},
+		},
+	})
+
+	logger := quicklogmocks.NewMockLogger(t)
+	logger.On("Log", mock.Anything, mock.Anything).Maybe()
+
+	authorizer, err := arpc.NewPolicyAuthorizer(&arpc.PolicyConfig{
+		Path:           path,
+		ReloadInterval: 10 * time.Millisecond,
+		Logger:         logger,
+	})
+	require.NoError(t, err)
+	defer authorizer.Close()
+
+	idTokenConfig := &arpc.IDTokenConfig{
+		Keys:      arpc.StaticKeySource{"key-1": &key.PublicKey},
+		Audiences: []string{"https://my-service.run.app"},
+	}
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			arpc.UnaryIDTokenInterceptor(idTokenConfig),
+			arpc.UnaryPolicyInterceptor(authorizer),
+		),
+		grpc.ChainStreamInterceptor(
+			arpc.StreamIDTokenInterceptor(idTokenConfig),
+			arpc.StreamPolicyInterceptor(authorizer),
+		),
+	}))
+	defer stub.Stop()
+
+	token, err := arpcmocks.SignIDToken(key, "key-1", idTokenClaims(nil))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	_, err = stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+	require.NoError(t, err)
+
+	_, err = stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Update the policy file, and wait for the change to be picked up.
+	writePolicy(path, &arpc.Policy{
+		DenyByDefault: true,
+		Rules: []arpc.PolicyRule{
+			{
+				Name:       "callers",
+				Effect:     arpc.PolicyAllow,
+				Methods:    []string{"/grpc.testing.TestService/*"},
+				Principals: []string{"caller@project.iam.gserviceaccount.com"},
+			},
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		_, err = stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		return err == nil
+	}, time.Second, 20*time.Millisecond)
+
+	// Unverified emails are not used as principal.
+	unverifiedToken, err := arpcmocks.SignIDToken(key, "key-1", idTokenClaims(map[string]interface{}{
+		"email_verified": false,
+	}))
+	require.NoError(t, err)
+
+	unverifiedCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+unverifiedToken)
+
+	_, err = stub.Client.EmptyCall(unverifiedCtx, new(testgrpc.Empty))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,274 @@
+package arpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimit configures a token bucket. The bucket holds up to Burst tokens, and is refilled at a pace of Rate
+// tokens per second. Every call consumes one token.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitStore keeps track of token buckets. Implement this interface to share limits between multiple
+// instances of a service.
+type RateLimitStore interface {
+	// Take consumes a token from the bucket identified by key. When no token is available, it returns false, along
+	// with the delay after which a token will be available. The delay is 0 if the bucket is never refilled.
+	Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error)
+	// Refund gives back a token consumed by Take, for calls rejected by another bucket.
+	Refund(ctx context.Context, key string, limit RateLimit) error
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+	limit    RateLimit
+}
+
+type memoryRateLimitStore struct {
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+
+	mu sync.Mutex
+}
+
+// Remove buckets that have been idle long enough to be full again. They behave exactly like new buckets.
+func (store *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < time.Minute {
+		return
+	}
+
+	store.lastSweep = now
+
+	for key, bucket := range store.buckets {
+		refill := now.Sub(bucket.lastSeen).Seconds() * bucket.limit.Rate
+		if bucket.tokens+refill >= float64(bucket.limit.Burst) {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+func (store *memoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	store.sweep(now)
+
+	bucket, ok := store.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), lastSeen: now}
+		store.buckets[key] = bucket
+	}
+
+	bucket.limit = limit
+
+	// Refill the bucket with the tokens earned since last call.
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*limit.Rate)
+	bucket.lastSeen = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+
+	if limit.Rate <= 0 {
+		return false, 0, nil
+	}
+
+	return false, time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second)), nil
+}
+
+func (store *memoryRateLimitStore) Refund(_ context.Context, key string, limit RateLimit) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if bucket, ok := store.buckets[key]; ok {
+		bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+1)
+	}
+
+	return nil
+}
+
+// NewMemoryRateLimitStore creates a RateLimitStore that keeps buckets in memory. Limits are not shared between
+// instances.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// RateLimitKeyFunc computes the key of the bucket used for a call. Calls sharing the same key share the same limit.
+type RateLimitKeyFunc func(ctx context.Context, fullMethod string) string
+
+// RateLimitByMethod shares a single limit among all callers of a method.
+func RateLimitByMethod() RateLimitKeyFunc {
+	return func(_ context.Context, fullMethod string) string {
+		return fullMethod
+	}
+}
+
+// RateLimitByPrincipal gives a separate limit to each caller, as returned by PrincipalFromContext. Anonymous callers
+// share the same limit.
+func RateLimitByPrincipal() RateLimitKeyFunc {
+	return func(ctx context.Context, _ string) string {
+		principal, _ := PrincipalFromContext(ctx)
+		return principal
+	}
+}
+
+// RateLimitByPeer gives a separate limit to each remote address, ignoring the port.
+func RateLimitByPeer() RateLimitKeyFunc {
+	return func(ctx context.Context, _ string) string {
+		pr, ok := peer.FromContext(ctx)
+		if !ok || pr.Addr == nil {
+			return ""
+		}
+
+		host, _, err := net.SplitHostPort(pr.Addr.String())
+		if err != nil {
+			return pr.Addr.String()
+		}
+
+		return host
+	}
+}
+
+// RateLimitByMetadata gives a separate limit to each value of an incoming metadata key, such as an API key.
+func RateLimitByMetadata(key string) RateLimitKeyFunc {
+	return func(ctx context.Context, _ string) string {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+}
+
+// RateLimitRule applies a limit to the methods matching any of the patterns. Patterns follow the syntax of
+// path.Match.
+type RateLimitRule struct {
+	Methods []string
+	// Key splits the limit between callers. Defaults to RateLimitByMethod.
+	Key   RateLimitKeyFunc
+	Limit RateLimit
+}
+
+// RateLimitConfig configures the rate limiting interceptors. Every rule matching a method is applied, so a call
+// is only allowed if every matching bucket grants it a token.
+type RateLimitConfig struct {
+	Rules []RateLimitRule
+	// Store defaults to an in-memory store.
+	Store RateLimitStore
+}
+
+func (config *RateLimitConfig) limit(ctx context.Context, fullMethod string) error {
+	type takenToken struct {
+		key   string
+		limit RateLimit
+	}
+
+	var taken []takenToken
+
+	for i, rule := range config.Rules {
+		if !matchAnyPattern(rule.Methods, fullMethod) {
+			continue
+		}
+
+		keyFunc := rule.Key
+		if keyFunc == nil {
+			keyFunc = RateLimitByMethod()
+		}
+
+		key := strconv.Itoa(i) + ":" + keyFunc(ctx, fullMethod)
+
+		allowed, retryAfter, err := config.Store.Take(ctx, key, rule.Limit)
+		// Fail open: a broken store should not take the whole service down.
+		if err != nil {
+			continue
+		}
+
+		if allowed {
+			taken = append(taken, takenToken{key: key, limit: rule.Limit})
+			continue
+		}
+
+		// The call is rejected, so it must not count against the buckets that granted it a token.
+		for _, token := range taken {
+			_ = config.Store.Refund(ctx, token.key, token.limit)
+		}
+
+		return rateLimitError(fullMethod, retryAfter)
+	}
+
+	return nil
+}
+
+func rateLimitError(fullMethod string, retryAfter time.Duration) error {
+	// The bucket is never refilled, so there is no point telling the client when to retry.
+	if retryAfter <= 0 {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s", fullMethod)
+	}
+
+	st, err := status.
+		New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded for %s, retry in %s", fullMethod, retryAfter)).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s, retry in %s", fullMethod, retryAfter)
+	}
+
+	return st.Err() //nolint:wrapcheck
+}
+
+func newRateLimitConfig(config *RateLimitConfig) *RateLimitConfig {
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+
+	return config
+}
+
+// UnaryRateLimitInterceptor rejects unary calls that exceed their limits, with codes.ResourceExhausted. The
+// status carries a RetryInfo detail, telling the client when to retry, unless the bucket is never refilled.
+func UnaryRateLimitInterceptor(config *RateLimitConfig) grpc.UnaryServerInterceptor {
+	config = newRateLimitConfig(config)
+
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := config.limit(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor rejects streams that exceed their limits, with codes.ResourceExhausted. Only the
+// opening of the stream is limited, not the messages it carries.
+func StreamRateLimitInterceptor(config *RateLimitConfig) grpc.StreamServerInterceptor {
+	config = newRateLimitConfig(config)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := config.limit(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,163 @@
+package arpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := arpc.NewMemoryRateLimitStore()
+	limit := arpc.RateLimit{Rate: 1, Burst: 2}
+
+	for range 2 {
+		allowed, _, err := store.Take(context.Background(), "foo", limit)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := store.Take(context.Background(), "foo", limit)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.InDelta(t, time.Second, retryAfter, float64(100*time.Millisecond))
+
+	// Refunded tokens can be taken again.
+	require.NoError(t, store.Refund(context.Background(), "foo", limit))
+
+	allowed, _, err = store.Take(context.Background(), "foo", limit)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// Buckets that are never refilled don't tell when to retry.
+	allowed, retryAfter, err = store.Take(context.Background(), "baz", arpc.RateLimit{})
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Zero(t, retryAfter)
+
+	// Buckets are independent.
+	allowed, _, err = store.Take(context.Background(), "bar", limit)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestRateLimitInterceptors(t *testing.T) {
+	config := &arpc.RateLimitConfig{
+		Rules: []arpc.RateLimitRule{
+			{
+				Methods: []string{"/grpc.testing.TestService/EmptyCall"},
+				Key:     arpc.RateLimitByMetadata("x-api-key"),
+				Limit:   arpc.RateLimit{Rate: 0.1, Burst: 2},
+			},
+		},
+	}
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryRateLimitInterceptor(config)),
+		grpc.ChainStreamInterceptor(arpc.StreamRateLimitInterceptor(config)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ctxA := metadata.AppendToOutgoingContext(ctx, "x-api-key", "a")
+	ctxB := metadata.AppendToOutgoingContext(ctx, "x-api-key", "b")
+
+	for range 2 {
+		_, err := stub.Client.EmptyCall(ctxA, new(testgrpc.Empty))
+		require.NoError(t, err)
+	}
+
+	_, err := stub.Client.EmptyCall(ctxA, new(testgrpc.Empty))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+
+	retryInfo, ok := details[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.InDelta(t, 10*time.Second, retryInfo.GetRetryDelay().AsDuration(), float64(time.Second))
+
+	// Other callers have their own limit.
+	_, err = stub.Client.EmptyCall(ctxB, new(testgrpc.Empty))
+	require.NoError(t, err)
+
+	// Other methods are not limited.
+	for range 5 {
+		_, err = stub.Client.UnaryCall(ctxA, new(testgrpc.SimpleRequest))
+		require.NoError(t, err)
+	}
+}
+
+func TestRateLimitRules(t *testing.T) {
+	config := &arpc.RateLimitConfig{
+		Rules: []arpc.RateLimitRule{
+			{
+				Methods: []string{"/grpc.testing.TestService/*"},
+				// Share the limit between all the methods of the service.
+				Key:   func(_ context.Context, _ string) string { return "" },
+				Limit: arpc.RateLimit{Rate: 0.1, Burst: 3},
+			},
+			{
+				Methods: []string{"/grpc.testing.TestService/EmptyCall"},
+				Limit:   arpc.RateLimit{Burst: 1},
+			},
+		},
+	}
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return new(testgrpc.SimpleResponse), nil
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryRateLimitInterceptor(config)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+	require.NoError(t, err)
+
+	// The second rule rejects the calls, and is never refilled, so no RetryInfo is sent.
+	for range 5 {
+		_, err = stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.Empty(t, status.Convert(err).Details())
+	}
+
+	// Rejected calls did not consume tokens from the first rule.
+	for range 2 {
+		_, err = stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.NoError(t, err)
+	}
+
+	_, err = stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
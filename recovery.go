@@ -0,0 +1,112 @@
+package arpc
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+// PanicCounter counts recovered panics per method. It is thread-safe, and can be polled to trigger alerts.
+type PanicCounter struct {
+	counts map[string]int
+	mu     sync.RWMutex
+}
+
+func (counter *PanicCounter) inc(fullMethod string) {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
+	if counter.counts == nil {
+		counter.counts = make(map[string]int)
+	}
+
+	counter.counts[fullMethod]++
+}
+
+// Count returns the number of panics recovered for a given method.
+func (counter *PanicCounter) Count(fullMethod string) int {
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+
+	return counter.counts[fullMethod]
+}
+
+// Counts returns a snapshot of the number of panics recovered, for every method that panicked at least once.
+func (counter *PanicCounter) Counts() map[string]int {
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+
+	output := make(map[string]int, len(counter.counts))
+	for method, count := range counter.counts {
+		output[method] = count
+	}
+
+	return output
+}
+
+// RecoveryConfig configures the recovery interceptors.
+type RecoveryConfig struct {
+	// Logger receives a message for every recovered panic, with its stack trace.
+	Logger quicklog.Logger
+	// Release hides the panic value from clients. When unset, the panic value is sent back in the status message,
+	// to ease debugging.
+	Release bool
+	// Counter is optional. When set, it is updated every time a panic is recovered.
+	Counter *PanicCounter
+}
+
+func (config *RecoveryConfig) recover(fullMethod string, value interface{}) error {
+	if config.Logger != nil {
+		config.Logger.Log(quicklog.LevelError, arpcmessages.NewPanic(fullMethod, value, debug.Stack()))
+	}
+
+	if config.Counter != nil {
+		config.Counter.inc(fullMethod)
+	}
+
+	if config.Release {
+		return status.Error(codes.Internal, "internal error") //nolint:wrapcheck
+	}
+
+	return status.Errorf(codes.Internal, "panic: %v", value)
+}
+
+// UnaryRecoveryInterceptor converts panics from unary handlers into codes.Internal errors, rather than crashing the
+// whole server.
+func UnaryRecoveryInterceptor(config *RecoveryConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				resp, err = nil, config.recover(info.FullMethod, value)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor converts panics from stream handlers into codes.Internal errors, rather than crashing
+// the whole server.
+func StreamRecoveryInterceptor(config *RecoveryConfig) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = config.recover(info.FullMethod, value)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
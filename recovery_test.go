@@ -0,0 +1,91 @@
+package arpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestRecoveryInterceptors(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		release bool
+
+		expectMessage string
+	}{
+		{
+			name: "Debug",
+
+			release: false,
+
+			expectMessage: "panic: uwups",
+		},
+		{
+			name: "Release",
+
+			release: true,
+
+			expectMessage: "internal error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			logger := quicklogmocks.NewMockLogger(t)
+			counter := new(arpc.PanicCounter)
+
+			stub := &arpcmocks.StubServer{
+				EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+					panic("uwups")
+				},
+				FullDuplexCallF: func(_ testgrpc.TestService_FullDuplexCallServer) error {
+					panic("uwups")
+				},
+			}
+
+			config := &arpc.RecoveryConfig{Logger: logger, Release: testCase.release, Counter: counter}
+
+			require.NoError(t, stub.Start([]grpc.ServerOption{
+				grpc.ChainUnaryInterceptor(arpc.UnaryRecoveryInterceptor(config)),
+				grpc.ChainStreamInterceptor(arpc.StreamRecoveryInterceptor(config)),
+			}))
+			defer stub.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			logger.On("Log", quicklog.LevelError, mock.Anything).Twice()
+
+			_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+			require.Equal(t, codes.Internal, status.Code(err))
+			require.Equal(t, testCase.expectMessage, status.Convert(err).Message())
+
+			stream, err := stub.Client.FullDuplexCall(ctx)
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			require.Equal(t, codes.Internal, status.Code(err))
+			require.Equal(t, testCase.expectMessage, status.Convert(err).Message())
+
+			require.Equal(t, 1, counter.Count("/grpc.testing.TestService/EmptyCall"))
+			require.Equal(t, map[string]int{
+				"/grpc.testing.TestService/EmptyCall":      1,
+				"/grpc.testing.TestService/FullDuplexCall": 1,
+			}, counter.Counts())
+		})
+	}
+}
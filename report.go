@@ -2,8 +2,11 @@ package arpc
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -26,6 +29,60 @@ func (s *wrappedService[In, Out]) Exec(ctx context.Context, data In) (Out, error
 	return s.service(ctx, data)
 }
 
+// Some errors are expected to happen under normal conditions, and should not be reported as errors.
+func reportLevel(err error) quicklog.Level {
+	if err == nil {
+		return quicklog.LevelInfo
+	}
+
+	code := status.Code(err)
+	if code == codes.Unavailable || code == codes.Canceled || code == codes.Unimplemented {
+		return quicklog.LevelWarning
+	}
+
+	return quicklog.LevelError
+}
+
+// reportAnnotations collects details about a call, from interceptors that run after the report interceptors.
+type reportAnnotations struct {
+	requestID      string
+	serverDeadline time.Duration
+
+	mu sync.Mutex
+}
+
+func (annotations *reportAnnotations) options() []arpcmessages.ReportOption {
+	annotations.mu.Lock()
+	defer annotations.mu.Unlock()
+
+	return []arpcmessages.ReportOption{
+		arpcmessages.WithRequestID(annotations.requestID),
+		arpcmessages.WithServerDeadline(annotations.serverDeadline),
+	}
+}
+
+type reportAnnotationsKey struct{}
+
+// Returns a context that inner interceptors can annotate. Values already present in the context are kept.
+func withReportAnnotations(ctx context.Context) (context.Context, *reportAnnotations) {
+	annotations := new(reportAnnotations)
+	annotations.requestID, _ = RequestIDFromContext(ctx)
+
+	return context.WithValue(ctx, reportAnnotationsKey{}, annotations), annotations
+}
+
+func annotateReport(ctx context.Context, annotate func(annotations *reportAnnotations)) {
+	annotations, ok := ctx.Value(reportAnnotationsKey{}).(*reportAnnotations)
+	if !ok {
+		return
+	}
+
+	annotations.mu.Lock()
+	defer annotations.mu.Unlock()
+
+	annotate(annotations)
+}
+
 func WithReport[In any, Out any](
 	name string, service ExecService[In, Out], logger quicklog.Logger,
 ) ExecService[In, Out] {
@@ -35,23 +92,108 @@ func WithReport[In any, Out any](
 			out, err := service.Exec(ctx, in)
 			end := time.Now()
 
-			level := quicklog.LevelInfo
-			if err != nil {
-				code := status.Code(err)
-				if code == codes.Unavailable || code == codes.Canceled || code == codes.Unimplemented {
-					level = quicklog.LevelWarning
-				} else {
-					level = quicklog.LevelError
-				}
-			}
-
-			logger.Log(level, arpcmessages.NewReport(
+			requestID, _ := RequestIDFromContext(ctx)
+			serverDeadline, _ := ServerDeadlineExceeded(ctx)
+
+			logger.Log(reportLevel(err), arpcmessages.NewReport(
 				&arpcmessages.Metrics{Latency: end.Sub(start)},
 				name,
 				err,
+				arpcmessages.WithRequestID(requestID),
+				arpcmessages.WithServerDeadline(serverDeadline),
 			))
 
 			return out, err //nolint:wrapcheck
 		},
 	}
 }
+
+// UnaryReportInterceptor reports every unary call handled by the server, the same way WithReport does. The full
+// method name is used as the service name.
+//
+// Methods matching any of the skip patterns are not reported. Patterns follow the syntax of path.Match, for
+// example "/grpc.health.v1.Health/*".
+func UnaryReportInterceptor(logger quicklog.Logger, skip ...string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if matchAnyPattern(skip, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, annotations := withReportAnnotations(ctx)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		end := time.Now()
+
+		logger.Log(reportLevel(err), arpcmessages.NewReport(
+			&arpcmessages.Metrics{Latency: end.Sub(start)},
+			info.FullMethod,
+			err,
+			annotations.options()...,
+		))
+
+		return resp, err
+	}
+}
+
+type reportedStream struct {
+	grpc.ServerStream
+
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (stream *reportedStream) SendMsg(m interface{}) error {
+	if err := stream.ServerStream.SendMsg(m); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	stream.sent.Add(1)
+
+	return nil
+}
+
+func (stream *reportedStream) RecvMsg(m interface{}) error {
+	if err := stream.ServerStream.RecvMsg(m); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	stream.received.Add(1)
+
+	return nil
+}
+
+// StreamReportInterceptor reports every stream handled by the server, once it terminates. Along with the total
+// duration of the stream, reports include the number of messages sent and received.
+//
+// Methods matching any of the skip patterns are not reported. Patterns follow the syntax of path.Match.
+func StreamReportInterceptor(logger quicklog.Logger, skip ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if matchAnyPattern(skip, info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, annotations := withReportAnnotations(ss.Context())
+		stream := &reportedStream{ServerStream: &contextServerStream{ServerStream: ss, ctx: ctx}}
+
+		start := time.Now()
+		err := handler(srv, stream)
+		end := time.Now()
+
+		logger.Log(reportLevel(err), arpcmessages.NewReport(
+			&arpcmessages.Metrics{
+				Latency:          end.Sub(start),
+				Stream:           true,
+				MessagesSent:     int(stream.sent.Load()),
+				MessagesReceived: int(stream.received.Load()),
+			},
+			info.FullMethod,
+			err,
+			annotations.options()...,
+		))
+
+		return err
+	}
+}
@@ -2,17 +2,22 @@ package arpc_test
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
 	"google.golang.org/grpc/status"
 
 	"github.com/a-novel-kit/quicklog"
 	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
 
 	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
 )
 
 type fakeExecService struct {
@@ -100,3 +105,94 @@ func TestWithReport(t *testing.T) {
 		})
 	}
 }
+
+func TestReportInterceptors(t *testing.T) {
+	logger := quicklogmocks.NewMockLogger(t)
+
+	stub := &arpcmocks.StubServer{
+		EmptyCallF: func(_ context.Context, _ *testgrpc.Empty) (*testgrpc.Empty, error) {
+			return new(testgrpc.Empty), nil
+		},
+		UnaryCallF: func(_ context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			return nil, status.Error(codes.NotFound, "uwups")
+		},
+		FullDuplexCallF: func(stream testgrpc.TestService_FullDuplexCallServer) error {
+			for {
+				if _, err := stream.Recv(); err != nil {
+					return nil //nolint:nilerr
+				}
+
+				if err := stream.Send(new(testgrpc.StreamingOutputCallResponse)); err != nil {
+					return err
+				}
+
+				if err := stream.Send(new(testgrpc.StreamingOutputCallResponse)); err != nil {
+					return err
+				}
+			}
+		},
+	}
+
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(arpc.UnaryReportInterceptor(logger, "/grpc.testing.TestService/EmptyCall")),
+		grpc.ChainStreamInterceptor(arpc.StreamReportInterceptor(logger)),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Skipped", func(t *testing.T) {
+		_, err := stub.Client.EmptyCall(ctx, new(testgrpc.Empty))
+		require.NoError(t, err)
+	})
+
+	t.Run("Unary", func(t *testing.T) {
+		logger.
+			On("Log", quicklog.LevelError, mock.MatchedBy(func(message quicklog.Message) bool {
+				request := message.RenderJSON()["grpcRequest"].(map[string]interface{})
+				return request["service"] == "/grpc.testing.TestService/UnaryCall" && request["code"] == codes.NotFound
+			})).
+			Once()
+
+		_, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest))
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		done := make(chan struct{})
+
+		logger.
+			On("Log", quicklog.LevelInfo, mock.MatchedBy(func(message quicklog.Message) bool {
+				request := message.RenderJSON()["grpcRequest"].(map[string]interface{})
+				return request["service"] == "/grpc.testing.TestService/FullDuplexCall" &&
+					request["stream"] == true &&
+					request["messagesReceived"] == 3 &&
+					request["messagesSent"] == 6
+			})).
+			Run(func(_ mock.Arguments) { close(done) }).
+			Once()
+
+		stream, err := stub.Client.FullDuplexCall(ctx)
+		require.NoError(t, err)
+
+		for range 3 {
+			require.NoError(t, stream.Send(new(testgrpc.StreamingOutputCallRequest)))
+			_, err = stream.Recv()
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+
+		// Reports are emitted server side, after the stream is closed.
+		select {
+		case <-done:
+		case <-ctx.Done():
+			require.Fail(t, "stream was not reported")
+		}
+	})
+}
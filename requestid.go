@@ -0,0 +1,109 @@
+package arpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"unicode"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultRequestIDHeader is the metadata key used to exchange request IDs, when none is configured.
+const DefaultRequestIDHeader = "x-request-id"
+
+// Incoming IDs longer than this are replaced, so callers cannot flood logs through the header.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID of the current request, as set by the request ID interceptors.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	// Read never fails, as stated by the documentation of crypto/rand.
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
+
+// Returns the ID sent by the caller, or a new one if none was provided. The ID is saved in the returned context.
+func resolveRequestID(ctx context.Context, header string) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := ""
+	if values := md.Get(header); len(values) > 0 && validRequestID(values[0]) {
+		requestID = values[0]
+	}
+
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+
+	annotateReport(ctx, func(annotations *reportAnnotations) {
+		annotations.requestID = requestID
+	})
+
+	return context.WithValue(ctx, requestIDKey{}, requestID), requestID
+}
+
+func requestIDHeader(header string) string {
+	if header == "" {
+		return DefaultRequestIDHeader
+	}
+
+	return header
+}
+
+// UnaryRequestIDInterceptor reads the ID of the request from the incoming metadata, or generates a new one when the
+// caller did not provide it. The ID is available through RequestIDFromContext, and is sent back to the caller in
+// the response header.
+//
+// An empty header name defaults to DefaultRequestIDHeader. Reports include the request ID, wherever this
+// interceptor sits in the chain.
+func UnaryRequestIDInterceptor(header string) grpc.UnaryServerInterceptor {
+	header = requestIDHeader(header)
+
+	return func(
+		ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, requestID := resolveRequestID(ctx, header)
+
+		// The response is still useful without the ID, so failing to set it is not an error.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(header, requestID))
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRequestIDInterceptor does the same as UnaryRequestIDInterceptor, for streams.
+func StreamRequestIDInterceptor(header string) grpc.StreamServerInterceptor {
+	header = requestIDHeader(header)
+
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, requestID := resolveRequestID(ss.Context(), header)
+
+		_ = ss.SetHeader(metadata.Pairs(header, requestID))
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,144 @@
+package arpc_test
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testgrpc "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+	arpcmocks "github.com/a-novel-kit/arpc/mocks"
+)
+
+func TestRequestIDInterceptors(t *testing.T) {
+	generatedID := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	logger := quicklogmocks.NewMockLogger(t)
+
+	stub := &arpcmocks.StubServer{
+		UnaryCallF: func(ctx context.Context, _ *testgrpc.SimpleRequest) (*testgrpc.SimpleResponse, error) {
+			requestID, ok := arpc.RequestIDFromContext(ctx)
+			if !ok {
+				return nil, status.Error(codes.Internal, "missing request ID")
+			}
+
+			return &testgrpc.SimpleResponse{Username: requestID}, nil
+		},
+		FullDuplexCallF: func(stream testgrpc.TestService_FullDuplexCallServer) error {
+			if _, ok := arpc.RequestIDFromContext(stream.Context()); !ok {
+				return status.Error(codes.Internal, "missing request ID")
+			}
+
+			return nil
+		},
+	}
+
+	// Report interceptors run first, and still receive the request ID.
+	require.NoError(t, stub.Start([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			arpc.UnaryReportInterceptor(logger),
+			arpc.UnaryRequestIDInterceptor(""),
+		),
+		grpc.ChainStreamInterceptor(
+			arpc.StreamReportInterceptor(logger),
+			arpc.StreamRequestIDInterceptor("x-correlation-id"),
+		),
+	}))
+	defer stub.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reportedID := func(expect func(requestID string) bool) interface{} {
+		return mock.MatchedBy(func(message quicklog.Message) bool {
+			request := message.RenderJSON()["grpcRequest"].(map[string]interface{})
+			requestID, _ := request["requestId"].(string)
+
+			return expect(requestID) && strings.Contains(message.RenderTerminal(), "#"+requestID)
+		})
+	}
+
+	t.Run("Forwarded", func(t *testing.T) {
+		logger.
+			On("Log", quicklog.LevelInfo, reportedID(func(requestID string) bool { return requestID == "abc-123" })).
+			Once()
+
+		var header, trailer metadata.MD
+
+		resp, err := stub.Client.UnaryCall(
+			metadata.AppendToOutgoingContext(ctx, arpc.DefaultRequestIDHeader, "abc-123"),
+			new(testgrpc.SimpleRequest),
+			grpc.Header(&header),
+			grpc.Trailer(&trailer),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, "abc-123", resp.GetUsername())
+		require.Equal(t, []string{"abc-123"}, header.Get(arpc.DefaultRequestIDHeader))
+		// The ID is only sent once, so proxies merging the header and trailer don't duplicate it.
+		require.Empty(t, trailer.Get(arpc.DefaultRequestIDHeader))
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		logger.On("Log", quicklog.LevelInfo, reportedID(generatedID.MatchString)).Once()
+
+		var header, trailer metadata.MD
+
+		resp, err := stub.Client.UnaryCall(ctx, new(testgrpc.SimpleRequest), grpc.Header(&header), grpc.Trailer(&trailer))
+		require.NoError(t, err)
+
+		require.Regexp(t, generatedID, resp.GetUsername())
+		require.Equal(t, []string{resp.GetUsername()}, header.Get(arpc.DefaultRequestIDHeader))
+		require.Empty(t, trailer.Get(arpc.DefaultRequestIDHeader))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		logger.On("Log", quicklog.LevelInfo, reportedID(generatedID.MatchString)).Once()
+
+		resp, err := stub.Client.UnaryCall(
+			metadata.AppendToOutgoingContext(ctx, arpc.DefaultRequestIDHeader, strings.Repeat("a", 200)),
+			new(testgrpc.SimpleRequest),
+		)
+		require.NoError(t, err)
+		require.Regexp(t, generatedID, resp.GetUsername())
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		done := make(chan struct{})
+
+		logger.
+			On("Log", quicklog.LevelInfo, reportedID(func(requestID string) bool { return requestID == "stream-id" })).
+			Run(func(_ mock.Arguments) { close(done) }).
+			Once()
+
+		stream, err := stub.Client.FullDuplexCall(metadata.AppendToOutgoingContext(ctx, "x-correlation-id", "stream-id"))
+		require.NoError(t, err)
+
+		header, err := stream.Header()
+		require.NoError(t, err)
+		require.Equal(t, []string{"stream-id"}, header.Get("x-correlation-id"))
+
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+		require.Empty(t, stream.Trailer().Get("x-correlation-id"))
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			require.Fail(t, "stream was not reported")
+		}
+	})
+}
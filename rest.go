@@ -0,0 +1,639 @@
+package arpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	ErrInvalidPathTemplate = errors.New("invalid path template")
+	ErrInvalidRESTField    = errors.New("invalid REST field")
+	ErrInvalidRESTBody     = errors.New("invalid REST body")
+)
+
+type restSegmentKind int
+
+const (
+	restLiteral restSegmentKind = iota
+	// Matches a single segment.
+	restWildcard
+	// Matches any number of segments. Only allowed at the end of a template.
+	restDeepWildcard
+)
+
+type restSegment struct {
+	kind    restSegmentKind
+	literal string
+}
+
+// restVariable binds the segments in [start, end) to a field of the request.
+type restVariable struct {
+	fieldPath  string
+	start, end int
+}
+
+// restTemplate is a parsed path template, as described in google/api/http.proto:
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+type restTemplate struct {
+	segments  []restSegment
+	variables []restVariable
+	verb      string
+}
+
+type restTemplateParser struct {
+	input    string
+	pos      int
+	template *restTemplate
+}
+
+func (parser *restTemplateParser) fail(reason string) error {
+	return fmt.Errorf("%w: %q at position %d: %s", ErrInvalidPathTemplate, parser.input, parser.pos, reason)
+}
+
+func (parser *restTemplateParser) parseSegments(inVariable bool) error {
+	for {
+		if err := parser.parseSegment(inVariable); err != nil {
+			return err
+		}
+
+		if parser.pos >= len(parser.input) || parser.input[parser.pos] != '/' {
+			return nil
+		}
+
+		parser.pos++
+	}
+}
+
+func (parser *restTemplateParser) parseSegment(inVariable bool) error {
+	rest := parser.input[parser.pos:]
+
+	switch {
+	case strings.HasPrefix(rest, "**"):
+		parser.template.segments = append(parser.template.segments, restSegment{kind: restDeepWildcard})
+		parser.pos += 2
+	case strings.HasPrefix(rest, "*"):
+		parser.template.segments = append(parser.template.segments, restSegment{kind: restWildcard})
+		parser.pos++
+	case strings.HasPrefix(rest, "{"):
+		if inVariable {
+			return parser.fail("nested variable")
+		}
+
+		return parser.parseVariable()
+	default:
+		end := strings.IndexAny(rest, "/}")
+		if end < 0 {
+			end = len(rest)
+		}
+
+		if end == 0 {
+			return parser.fail("empty segment")
+		}
+
+		parser.template.segments = append(parser.template.segments, restSegment{kind: restLiteral, literal: rest[:end]})
+		parser.pos += end
+	}
+
+	return nil
+}
+
+func (parser *restTemplateParser) parseVariable() error {
+	// Skip the opening brace.
+	parser.pos++
+
+	rest := parser.input[parser.pos:]
+
+	end := strings.IndexAny(rest, "=}")
+	if end <= 0 {
+		return parser.fail("invalid variable")
+	}
+
+	variable := restVariable{fieldPath: rest[:end], start: len(parser.template.segments)}
+	parser.pos += end
+
+	if parser.input[parser.pos] == '=' {
+		parser.pos++
+
+		if err := parser.parseSegments(true); err != nil {
+			return err
+		}
+	} else {
+		parser.template.segments = append(parser.template.segments, restSegment{kind: restWildcard})
+	}
+
+	if parser.pos >= len(parser.input) || parser.input[parser.pos] != '}' {
+		return parser.fail("unterminated variable")
+	}
+
+	parser.pos++
+
+	variable.end = len(parser.template.segments)
+	parser.template.variables = append(parser.template.variables, variable)
+
+	return nil
+}
+
+func parseRESTTemplate(template string) (*restTemplate, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("%w: %q: must start with a slash", ErrInvalidPathTemplate, template)
+	}
+
+	output := new(restTemplate)
+
+	// The verb follows the last segment, which may be a variable.
+	if i := strings.LastIndex(template, ":"); i > strings.LastIndexAny(template, "/}") {
+		output.verb = template[i+1:]
+		template = template[:i]
+	}
+
+	if template == "/" {
+		return output, nil
+	}
+
+	parser := &restTemplateParser{input: template, pos: 1, template: output}
+	if err := parser.parseSegments(false); err != nil {
+		return nil, err
+	}
+
+	if parser.pos != len(template) {
+		return nil, parser.fail("unexpected character")
+	}
+
+	for i, segment := range output.segments {
+		if segment.kind == restDeepWildcard && i != len(output.segments)-1 {
+			return nil, fmt.Errorf("%w: %q: ** must be the last segment", ErrInvalidPathTemplate, template)
+		}
+	}
+
+	return output, nil
+}
+
+// literals returns the number of literal segments, used to try the most specific templates first.
+func (template *restTemplate) literals() int {
+	count := 0
+
+	for _, segment := range template.segments {
+		if segment.kind == restLiteral {
+			count++
+		}
+	}
+
+	return count
+}
+
+// match returns the values of the variables of the template, when the escaped path matches it.
+func (template *restTemplate) match(path string) (map[string]string, bool) {
+	if template.verb != "" {
+		var ok bool
+		if path, ok = strings.CutSuffix(path, ":"+template.verb); !ok {
+			return nil, false
+		}
+	}
+
+	if len(template.segments) == 0 {
+		return map[string]string{}, path == "/"
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	deep := template.segments[len(template.segments)-1].kind == restDeepWildcard
+	if (!deep && len(parts) != len(template.segments)) || (deep && len(parts) < len(template.segments)-1) {
+		return nil, false
+	}
+
+	for i, segment := range template.segments {
+		if segment.kind == restLiteral && unescapePathSegment(parts[i]) != segment.literal {
+			return nil, false
+		}
+
+		if segment.kind == restWildcard && parts[i] == "" {
+			return nil, false
+		}
+	}
+
+	values := make(map[string]string, len(template.variables))
+
+	for _, variable := range template.variables {
+		end := variable.end
+		if end == len(template.segments) && deep {
+			end = len(parts)
+		}
+
+		segments := make([]string, 0, end-variable.start)
+		for _, part := range parts[variable.start:end] {
+			segments = append(segments, unescapePathSegment(part))
+		}
+
+		values[variable.fieldPath] = strings.Join(segments, "/")
+	}
+
+	return values, true
+}
+
+func unescapePathSegment(segment string) string {
+	unescaped, err := url.PathUnescape(segment)
+	if err != nil {
+		return segment
+	}
+
+	return unescaped
+}
+
+type restRoute struct {
+	method     *gatewayMethod
+	httpMethod string
+	template   *restTemplate
+	// Body is the field of the request mapped to the HTTP body. "*" maps the whole request, and an empty value
+	// means the request has no body.
+	body string
+	// ResponseBody is the field of the response mapped to the HTTP body. An empty value maps the whole response.
+	responseBody string
+}
+
+// httpRule reads the google.api.http annotation of a method. Options are decoded again, in case they were parsed
+// before the annotation extension was registered.
+func httpRule(method protoreflect.MethodDescriptor) *annotations.HttpRule {
+	data, err := proto.Marshal(method.Options())
+	if err != nil {
+		return nil
+	}
+
+	options := new(descriptorpb.MethodOptions)
+	if err = (proto.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}).Unmarshal(data, options); err != nil {
+		return nil
+	}
+
+	rule, _ := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+
+	return rule
+}
+
+func newRESTRoute(method *gatewayMethod, rule *annotations.HttpRule) (*restRoute, error) {
+	route := &restRoute{method: method, body: rule.GetBody(), responseBody: rule.GetResponseBody()}
+
+	var path string
+
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		route.httpMethod, path = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		route.httpMethod, path = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		route.httpMethod, path = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		route.httpMethod, path = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		route.httpMethod, path = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		route.httpMethod, path = pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return nil, fmt.Errorf("%w: %s: no pattern", ErrInvalidPathTemplate, method.fullMethod)
+	}
+
+	template, err := parseRESTTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	route.template = template
+
+	return route, nil
+}
+
+// loadRoutes builds the REST routes of the unary methods annotated with google.api.http.
+func (gateway *Gateway) loadRoutes(method *gatewayMethod, descriptor protoreflect.MethodDescriptor) error {
+	if method.clientStream || method.serverStream {
+		return nil
+	}
+
+	rule := httpRule(descriptor)
+	if rule == nil {
+		return nil
+	}
+
+	for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		route, err := newRESTRoute(method, binding)
+		if err != nil {
+			return fmt.Errorf("load routes of %s: %w", method.fullMethod, err)
+		}
+
+		gateway.routes = append(gateway.routes, route)
+	}
+
+	return nil
+}
+
+// Literal segments take precedence over variables, so "/v1/users/me" wins over "/v1/users/{id}".
+func (gateway *Gateway) sortRoutes() {
+	slices.SortStableFunc(gateway.routes, func(a, b *restRoute) int {
+		if diff := b.template.literals() - a.template.literals(); diff != 0 {
+			return diff
+		}
+
+		return strings.Compare(a.method.fullMethod, b.method.fullMethod)
+	})
+}
+
+func (gateway *Gateway) route(r *http.Request) (*restRoute, map[string]string, bool) {
+	for _, route := range gateway.routes {
+		if route.httpMethod != r.Method {
+			continue
+		}
+
+		if values, ok := route.template.match(r.URL.EscapedPath()); ok {
+			return route, values, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+func findField(message protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if field := message.Fields().ByName(protoreflect.Name(name)); field != nil {
+		return field
+	}
+
+	return message.Fields().ByJSONName(name)
+}
+
+// parseFieldValue converts a path or query parameter to the value of a field.
+func parseFieldValue(
+	field protoreflect.FieldDescriptor, raw string, newMessage func() protoreflect.Message,
+) (protoreflect.Value, error) {
+	var (
+		value protoreflect.Value
+		err   error
+	)
+
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		var parsed bool
+		parsed, err = strconv.ParseBool(raw)
+		value = protoreflect.ValueOfBool(parsed)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var parsed int64
+		parsed, err = strconv.ParseInt(raw, 10, 32)
+		value = protoreflect.ValueOfInt32(int32(parsed))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var parsed int64
+		parsed, err = strconv.ParseInt(raw, 10, 64)
+		value = protoreflect.ValueOfInt64(parsed)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var parsed uint64
+		parsed, err = strconv.ParseUint(raw, 10, 32)
+		value = protoreflect.ValueOfUint32(uint32(parsed))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var parsed uint64
+		parsed, err = strconv.ParseUint(raw, 10, 64)
+		value = protoreflect.ValueOfUint64(parsed)
+	case protoreflect.FloatKind:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(raw, 32)
+		value = protoreflect.ValueOfFloat32(float32(parsed))
+	case protoreflect.DoubleKind:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(raw, 64)
+		value = protoreflect.ValueOfFloat64(parsed)
+	case protoreflect.StringKind:
+		value = protoreflect.ValueOfString(raw)
+	case protoreflect.BytesKind:
+		var parsed []byte
+		if parsed, err = base64.StdEncoding.DecodeString(raw); err != nil {
+			parsed, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(raw, "="))
+		}
+
+		value = protoreflect.ValueOfBytes(parsed)
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByName(protoreflect.Name(raw)); enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number()), nil
+		}
+
+		var parsed int64
+		parsed, err = strconv.ParseInt(raw, 10, 32)
+		value = protoreflect.ValueOfEnum(protoreflect.EnumNumber(parsed))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// Well-known types, such as timestamps or wrappers, have a JSON representation that is either a string or
+		// a literal.
+		message := newMessage()
+		if err = protojsonUnmarshal(strconv.Quote(raw), message); err != nil {
+			err = protojsonUnmarshal(raw, message)
+		}
+
+		value = protoreflect.ValueOfMessage(message)
+	}
+
+	if err != nil {
+		return protoreflect.Value{}, fmt.Errorf("%w: %s: %w", ErrInvalidRESTField, field.FullName(), err)
+	}
+
+	return value, nil
+}
+
+// setField sets the value of a field of the request, designated by a dotted path of proto or JSON names.
+func setField(message protoreflect.Message, fieldPath string, values []string) error {
+	names := strings.Split(fieldPath, ".")
+
+	for i, name := range names {
+		field := findField(message.Descriptor(), name)
+		if field == nil {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidRESTField, fieldPath)
+		}
+
+		if i < len(names)-1 {
+			if field.Message() == nil || field.IsList() || field.IsMap() {
+				return fmt.Errorf("%w: %q is not a message", ErrInvalidRESTField, strings.Join(names[:i+1], "."))
+			}
+
+			message = message.Mutable(field).Message()
+
+			continue
+		}
+
+		if field.IsMap() {
+			return fmt.Errorf("%w: map field %q cannot be set from parameters", ErrInvalidRESTField, fieldPath)
+		}
+
+		if field.IsList() {
+			list := message.Mutable(field).List()
+
+			for _, raw := range values {
+				value, err := parseFieldValue(field, raw, func() protoreflect.Message {
+					return list.NewElement().Message()
+				})
+				if err != nil {
+					return err
+				}
+
+				list.Append(value)
+			}
+
+			return nil
+		}
+
+		parent := message
+
+		value, err := parseFieldValue(field, values[len(values)-1], func() protoreflect.Message {
+			return parent.NewField(field).Message()
+		})
+		if err != nil {
+			return err
+		}
+
+		message.Set(field, value)
+	}
+
+	return nil
+}
+
+func protojsonUnmarshal(data string, message protoreflect.Message) error {
+	return protojson.Unmarshal([]byte(data), message.Interface()) //nolint:wrapcheck
+}
+
+// decodeRESTBody merges the HTTP body into the request, according to the body mapping of the route.
+func (gateway *Gateway) decodeRESTBody(body []byte, route *restRoute, in protoreflect.Message) error {
+	if route.body != "*" {
+		field := findField(in.Descriptor(), route.body)
+		if field == nil {
+			return fmt.Errorf("%w: unknown body field %q", ErrInvalidRESTField, route.body)
+		}
+
+		// The body is wrapped below, so it must hold a single value, or it could set other fields.
+		if !json.Valid(body) {
+			return fmt.Errorf("decode request body: %w", ErrInvalidRESTBody)
+		}
+
+		// Let protojson decode the field, whatever its type.
+		decoded := in.New()
+		wrapped := slices.Concat([]byte(`{"`+field.JSONName()+`":`), body, []byte("}"))
+
+		if err := gateway.config.UnmarshalOptions.Unmarshal(wrapped, decoded.Interface()); err != nil {
+			return fmt.Errorf("decode request body: %w", err)
+		}
+
+		if decoded.Has(field) {
+			in.Set(field, decoded.Get(field))
+		}
+
+		return nil
+	}
+
+	decoded := in.New().Interface()
+	if err := gateway.config.UnmarshalOptions.Unmarshal(body, decoded); err != nil {
+		return fmt.Errorf("decode request body: %w", err)
+	}
+
+	proto.Merge(in.Interface(), decoded)
+
+	return nil
+}
+
+func (gateway *Gateway) serveREST(w http.ResponseWriter, r *http.Request, route *restRoute, values map[string]string) {
+	in := route.method.input.New()
+
+	if route.body != "" {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, gateway.config.MaxBodySize))
+		if err != nil {
+			gateway.writeError(w, status.Newf(codes.InvalidArgument, "read request body: %v", err))
+			return
+		}
+
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err = gateway.decodeRESTBody(body, route, in); err != nil {
+				gateway.writeError(w, status.New(codes.InvalidArgument, err.Error()))
+				return
+			}
+		}
+	}
+
+	// Fields that are not bound by the body or path can be set through query parameters. Path parameters take
+	// precedence over them.
+	if route.body != "*" {
+		for key, params := range r.URL.Query() {
+			if err := setField(in, key, params); err != nil {
+				gateway.writeError(w, status.New(codes.InvalidArgument, err.Error()))
+				return
+			}
+		}
+	}
+
+	for fieldPath, value := range values {
+		if err := setField(in, fieldPath, []string{value}); err != nil {
+			gateway.writeError(w, status.New(codes.InvalidArgument, err.Error()))
+			return
+		}
+	}
+
+	out := route.method.output.New().Interface()
+
+	header, st := gateway.invoke(r, route.method.fullMethod, in.Interface(), out)
+	copyGatewayHeader(w.Header(), header)
+
+	if st.Code() != codes.OK {
+		gateway.writeError(w, st)
+		return
+	}
+
+	if route.responseBody == "" {
+		gateway.writeJSON(w, http.StatusOK, out)
+		return
+	}
+
+	gateway.writeResponseField(w, out, route.responseBody)
+}
+
+// writeResponseField writes a single field of the response, as mapped by the response_body option.
+func (gateway *Gateway) writeResponseField(w http.ResponseWriter, out proto.Message, fieldName string) {
+	field := findField(out.ProtoReflect().Descriptor(), fieldName)
+	if field == nil {
+		gateway.writeError(w, status.Newf(codes.Internal, "unknown response field %q", fieldName))
+		return
+	}
+
+	options := gateway.config.MarshalOptions
+	options.EmitUnpopulated = true
+
+	data, err := options.Marshal(out)
+	if err != nil {
+		gateway.writeError(w, status.Newf(codes.Internal, "encode response: %v", err))
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		gateway.writeError(w, status.Newf(codes.Internal, "encode response: %v", err))
+		return
+	}
+
+	key := field.JSONName()
+	if options.UseProtoNames {
+		key = field.TextName()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(fields[key])
+}
@@ -0,0 +1,440 @@
+package arpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/a-novel-kit/arpc"
+)
+
+var errBookNotFound = errors.New("book not found")
+
+var registerLibrary = sync.OnceValue(func() protoreflect.FileDescriptor {
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     kind.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+
+	tags := field("tags", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	book := field("book", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	book.TypeName = proto.String(".arpc.test.Book")
+
+	method := func(name, input string, rule *annotations.HttpRule) *descriptorpb.MethodDescriptorProto {
+		options := new(descriptorpb.MethodOptions)
+		proto.SetExtension(options, annotations.E_Http, rule)
+
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".arpc.test." + input),
+			OutputType: proto.String(".arpc.test.Book"),
+			Options:    options,
+		}
+	}
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("arpc/test/library.proto"),
+		Package: proto.String("arpc.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Book"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("shelf", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("id", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+					field("title", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					tags,
+					field("full", 5, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+				},
+			},
+			{
+				Name: proto.String("GetBookRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("shelf", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("id", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+					tags,
+					field("full", 5, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+				},
+			},
+			{
+				Name: proto.String("CreateBookRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("shelf", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					book,
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Library"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					method("GetBook", "GetBookRequest", &annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Get{Get: "/v1/shelves/{shelf}/books/{id}"},
+						AdditionalBindings: []*annotations.HttpRule{
+							{Pattern: &annotations.HttpRule_Get{Get: "/v1/books/{id}"}},
+						},
+					}),
+					method("CreateBook", "CreateBookRequest", &annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Post{Post: "/v1/shelves/{shelf}/books"},
+						Body:    "book",
+					}),
+					method("UpdateBook", "Book", &annotations.HttpRule{
+						Pattern:      &annotations.HttpRule_Patch{Patch: "/v1/{shelf=shelves/*}/books/{id}:update"},
+						Body:         "*",
+						ResponseBody: "title",
+					}),
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+
+	if err = protoregistry.GlobalFiles.RegisterFile(file); err != nil {
+		panic(err)
+	}
+
+	return file
+})
+
+var registerInvalidLibrary = sync.OnceValue(func() protoreflect.FileDescriptor {
+	options := new(descriptorpb.MethodOptions)
+	proto.SetExtension(options, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/books/{id"},
+	})
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String("arpc/test/invalid_library.proto"),
+		Package:     proto.String("arpc.test"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("InvalidBook")}},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("InvalidLibrary"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("GetBook"),
+						InputType:  proto.String(".arpc.test.InvalidBook"),
+						OutputType: proto.String(".arpc.test.InvalidBook"),
+						Options:    options,
+					},
+				},
+			},
+		},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+
+	if err = protoregistry.GlobalFiles.RegisterFile(file); err != nil {
+		panic(err)
+	}
+
+	return file
+})
+
+// Serve the library with dynamic messages, as no code is generated for it.
+func libraryServiceDesc(file protoreflect.FileDescriptor) *grpc.ServiceDesc {
+	service := file.Services().ByName("Library")
+	bookDescriptor := file.Messages().ByName("Book")
+
+	handler := func(
+		name string, exec func(in *dynamicpb.Message) (proto.Message, error),
+	) grpc.MethodDesc {
+		input := service.Methods().ByName(protoreflect.Name(name)).Input()
+
+		return grpc.MethodDesc{
+			MethodName: name,
+			Handler: func(
+				_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+			) (interface{}, error) {
+				in := dynamicpb.NewMessage(input)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				if interceptor == nil {
+					return exec(in)
+				}
+
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: "/arpc.test.Library/" + name},
+					func(_ context.Context, req interface{}) (interface{}, error) {
+						return exec(req.(*dynamicpb.Message))
+					})
+			},
+		}
+	}
+
+	errorHandler := arpc.HandleError(codes.Internal).Is(errBookNotFound, codes.NotFound)
+
+	// Book shares its field numbers with the requests, so they can be converted through the wire format.
+	toBook := func(message proto.Message) *dynamicpb.Message {
+		data, err := proto.Marshal(message)
+		if err != nil {
+			panic(err)
+		}
+
+		out := dynamicpb.NewMessage(bookDescriptor)
+		if err = proto.Unmarshal(data, out); err != nil {
+			panic(err)
+		}
+
+		return out
+	}
+
+	return &grpc.ServiceDesc{
+		ServiceName: "arpc.test.Library",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			handler("GetBook", func(in *dynamicpb.Message) (proto.Message, error) {
+				out := toBook(in)
+				if id := out.Get(bookDescriptor.Fields().ByName("id")).Int(); id == 404 {
+					return nil, errorHandler.Handle(fmt.Errorf("%w: %d", errBookNotFound, id))
+				}
+
+				out.Set(bookDescriptor.Fields().ByName("title"), protoreflect.ValueOfString("Dune"))
+
+				return out, nil
+			}),
+			handler("CreateBook", func(in *dynamicpb.Message) (proto.Message, error) {
+				out := toBook(in.Get(in.Descriptor().Fields().ByName("book")).Message().Interface())
+				out.Set(bookDescriptor.Fields().ByName("shelf"), in.Get(in.Descriptor().Fields().ByName("shelf")))
+
+				return out, nil
+			}),
+			handler("UpdateBook", func(in *dynamicpb.Message) (proto.Message, error) {
+				return in, nil
+			}),
+		},
+		Metadata: "arpc/test/library.proto",
+	}
+}
+
+func TestGatewayREST(t *testing.T) {
+	file := registerLibrary()
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
+	require.NoError(t, err)
+
+	server.RegisterService(libraryServiceDesc(file), struct{}{})
+
+	gateway, err := arpc.NewGateway(server, nil)
+	require.NoError(t, err)
+
+	go func() {
+		_ = gateway.Serve(listener)
+	}()
+
+	defer func() {
+		require.NoError(t, gateway.Close())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		name string
+
+		method string
+		path   string
+		body   string
+
+		expectStatus int
+		expectBody   string
+	}{
+		{
+			name: "PathAndQuery",
+
+			method: http.MethodGet,
+			path:   "/v1/shelves/fiction/books/42?full=true&tags=a&tags=b",
+
+			expectStatus: http.StatusOK,
+			expectBody:   `{"shelf":"fiction","id":"42","title":"Dune","tags":["a","b"],"full":true}`,
+		},
+		{
+			name: "AdditionalBinding",
+
+			method: http.MethodGet,
+			path:   "/v1/books/7",
+
+			expectStatus: http.StatusOK,
+			expectBody:   `{"id":"7","title":"Dune"}`,
+		},
+		{
+			name: "EscapedPath",
+
+			method: http.MethodGet,
+			path:   "/v1/shelves/sci%2Ffi/books/1",
+
+			expectStatus: http.StatusOK,
+			expectBody:   `{"shelf":"sci/fi","id":"1","title":"Dune"}`,
+		},
+		{
+			name: "BodyField",
+
+			method: http.MethodPost,
+			path:   "/v1/shelves/fiction/books",
+			body:   `{"id":"3","title":"Hyperion"}`,
+
+			expectStatus: http.StatusOK,
+			expectBody:   `{"shelf":"fiction","id":"3","title":"Hyperion"}`,
+		},
+		{
+			name: "WholeBodyAndResponseBody",
+
+			method: http.MethodPatch,
+			path:   "/v1/shelves/fiction/books/3:update",
+			body:   `{"title":"Foundation"}`,
+
+			expectStatus: http.StatusOK,
+			expectBody:   `"Foundation"`,
+		},
+		{
+			name: "ErrorHandlerStatus",
+
+			method: http.MethodGet,
+			path:   "/v1/books/404",
+
+			expectStatus: http.StatusNotFound,
+			expectBody:   `{"code":5,"message":"book not found: 404"}`,
+		},
+		{
+			name: "InvalidPathParameter",
+
+			method: http.MethodGet,
+			path:   "/v1/books/abc",
+
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name: "UnknownQueryParameter",
+
+			method: http.MethodGet,
+			path:   "/v1/books/1?unknown=1",
+
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name: "InvalidBody",
+
+			method: http.MethodPost,
+			path:   "/v1/shelves/fiction/books",
+			body:   `{"id":`,
+
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name: "BodyFieldOverflow",
+
+			method: http.MethodPost,
+			path:   "/v1/shelves/fiction/books",
+			body:   `{"title":"Hyperion"}, "shelf": "horror"`,
+
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name: "LiteralCase",
+
+			method: http.MethodGet,
+			path:   "/V1/books/7",
+
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name: "MethodMismatch",
+
+			method: http.MethodDelete,
+			path:   "/v1/books/1",
+
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name: "VerbMismatch",
+
+			method: http.MethodPatch,
+			path:   "/v1/shelves/fiction/books/3",
+			body:   `{}`,
+
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(
+				ctx, testCase.method, "http://"+listener.Addr().String()+testCase.path, strings.NewReader(testCase.body),
+			)
+			require.NoError(t, err)
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, testCase.expectStatus, res.StatusCode, string(body))
+			require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+			if testCase.expectBody != "" {
+				require.JSONEq(t, testCase.expectBody, string(body))
+			}
+		})
+	}
+}
+
+func TestGatewayRESTInvalidTemplate(t *testing.T) {
+	file := registerInvalidLibrary()
+
+	_, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
+	require.NoError(t, err)
+
+	defer server.Stop()
+
+	service := file.Services().ByName("InvalidLibrary")
+
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: string(service.FullName()),
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "GetBook",
+				Handler: func(_ interface{}, _ context.Context, _ func(interface{}) error, _ grpc.UnaryServerInterceptor) (
+					interface{}, error,
+				) {
+					return nil, nil
+				},
+			},
+		},
+		Metadata: file.Path(),
+	}, struct{}{})
+
+	_, err = arpc.NewGateway(server, nil)
+	require.ErrorIs(t, err, arpc.ErrInvalidPathTemplate)
+}
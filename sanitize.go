@@ -0,0 +1,57 @@
+package arpc
+
+import (
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+
+	arpcmessages "github.com/a-novel-kit/arpc/messages"
+)
+
+// SanitizeConfig hides the message of some errors from clients, as they may leak sensitive information, such as
+// SQL queries or internal hostnames.
+type SanitizeConfig struct {
+	// Release enables sanitization. Messages are sent as is otherwise, to ease debugging.
+	Release bool
+	// Codes of the statuses to sanitize. Defaults to codes.Internal and codes.Unknown.
+	Codes []codes.Code
+	// Message replaces the message of sanitized statuses. Defaults to "internal error".
+	Message string
+	// Logger receives the full error, along with the ID sent to the client, so it can be looked up.
+	Logger quicklog.Logger
+}
+
+// Replaces the message of the status with a generic one, if its code must be sanitized.
+func (config *SanitizeConfig) sanitize(err error, handled error) error {
+	if config == nil || !config.Release || err == nil {
+		return handled
+	}
+
+	st := status.Convert(handled)
+
+	sanitizedCodes := config.Codes
+	if len(sanitizedCodes) == 0 {
+		sanitizedCodes = []codes.Code{codes.Internal, codes.Unknown}
+	}
+
+	if !slices.Contains(sanitizedCodes, st.Code()) {
+		return handled
+	}
+
+	message := config.Message
+	if message == "" {
+		message = "internal error"
+	}
+
+	// Error IDs are random, so they don't reveal anything about the error.
+	errorID := newRequestID()
+
+	if config.Logger != nil {
+		config.Logger.Log(quicklog.LevelError, arpcmessages.NewSanitizedError(errorID, st.Code(), err))
+	}
+
+	return status.Errorf(st.Code(), "%s (error ID: %s)", message, errorID)
+}
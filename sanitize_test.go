@@ -0,0 +1,87 @@
+package arpc_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/quicklog"
+	quicklogmocks "github.com/a-novel-kit/quicklog/mocks"
+
+	"github.com/a-novel-kit/arpc"
+)
+
+func TestHandleErrorSanitize(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errDatabase := errors.New("pq: connection to db-internal-42.local refused")
+
+	errorIDRegexp := regexp.MustCompile(`^internal error \(error ID: ([0-9a-f]{32})\)$`)
+
+	t.Run("Release", func(t *testing.T) {
+		logger := quicklogmocks.NewMockLogger(t)
+
+		handler := arpc.HandleError(codes.Internal).
+			Is(errNotFound, codes.NotFound).
+			Sanitize(&arpc.SanitizeConfig{Release: true, Logger: logger})
+
+		var loggedID string
+
+		logger.
+			On("Log", quicklog.LevelError, mock.MatchedBy(func(message quicklog.Message) bool {
+				return message.RenderJSON()["error"] == errDatabase.Error()
+			})).
+			Run(func(args mock.Arguments) {
+				loggedID = args.Get(1).(quicklog.Message).RenderJSON()["errorId"].(string)
+			}).
+			Once()
+
+		st := status.Convert(handler.Handle(errDatabase))
+		require.Equal(t, codes.Internal, st.Code())
+
+		matches := errorIDRegexp.FindStringSubmatch(st.Message())
+		require.Len(t, matches, 2, st.Message())
+		require.Equal(t, loggedID, matches[1])
+
+		// Other codes are not sanitized.
+		st = status.Convert(handler.Handle(errNotFound))
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, "not found", st.Message())
+	})
+
+	t.Run("CustomCodes", func(t *testing.T) {
+		handler := arpc.HandleError(codes.Internal).
+			Is(errNotFound, codes.NotFound).
+			Sanitize(&arpc.SanitizeConfig{Release: true, Codes: []codes.Code{codes.NotFound}, Message: "oops"})
+
+		st := status.Convert(handler.Handle(errNotFound))
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Regexp(t, `^oops \(error ID: [0-9a-f]{32}\)$`, st.Message())
+
+		st = status.Convert(handler.Handle(errDatabase))
+		require.Equal(t, codes.Internal, st.Code())
+		require.Equal(t, errDatabase.Error(), st.Message())
+	})
+
+	t.Run("Upstream", func(t *testing.T) {
+		handler := arpc.HandleError(codes.Internal).
+			Passthrough(arpc.StatusPolicy{Mode: arpc.StatusKeep}).
+			Sanitize(&arpc.SanitizeConfig{Release: true})
+
+		st := status.Convert(handler.Handle(status.Error(codes.Unknown, errDatabase.Error())))
+		require.Equal(t, codes.Unknown, st.Code())
+		require.NotContains(t, st.Message(), "db-internal-42")
+	})
+
+	t.Run("Debug", func(t *testing.T) {
+		handler := arpc.HandleError(codes.Internal).Sanitize(&arpc.SanitizeConfig{Release: false})
+
+		st := status.Convert(handler.Handle(errDatabase))
+		require.Equal(t, codes.Internal, st.Code())
+		require.Equal(t, errDatabase.Error(), st.Message())
+	})
+}
@@ -1,35 +1,127 @@
 package arpc
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"strconv"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
-var ErrPortRequired = errors.New("port is required")
+// PortEnv is the environment variable used by Cloud Run to communicate the port a service must listen on.
+const PortEnv = "PORT"
+
+var (
+	ErrPortRequired = errors.New("port is required")
+	ErrInvalidPort  = errors.New("invalid port")
+)
+
+// ServerConfig configures the listener created by StartServerWithConfig.
+type ServerConfig struct {
+	// Port to listen on. Port 0 is rejected, unless Ephemeral is set.
+	Port int
+	// Ephemeral lets the system pick a free port when Port is 0. The actual port can be retrieved from the
+	// returned listener, using ListenerPort.
+	Ephemeral bool
+	// Host binds the listener to a specific interface. Empty means all interfaces.
+	Host string
+
+	// UnixSocket makes the server listen on a unix domain socket, instead of a TCP port.
+	UnixSocket string
+	// Listener is a custom listener provided by the caller. It takes precedence over every other listening
+	// option, and is closed alongside the server.
+	Listener net.Listener
+
+	// TLS secures the server with the given configuration. Use ServerTLSConfig to require clients to present
+	// a certificate.
+	TLS *tls.Config
+
+	// Options are passed to the GRPC server on creation. Use them to register interceptors.
+	//
+	//	Options: []grpc.ServerOption{
+	//		grpc.ChainUnaryInterceptor(arpc.UnaryReportInterceptor(logger)),
+	//		grpc.ChainStreamInterceptor(arpc.StreamReportInterceptor(logger)),
+	//	},
+	Options []grpc.ServerOption
+
+	// Admin serves the admin endpoint on a side port, which is closed alongside the server. To serve it on the
+	// same port as the server instead, use GatewayConfig.Admin.
+	Admin *AdminConfig
+}
+
+func (config *ServerConfig) listen() (net.Listener, error) {
+	if config.Listener != nil {
+		return config.Listener, nil
+	}
+
+	if config.UnixSocket != "" {
+		listener, err := net.Listen("unix", config.UnixSocket)
+		if err != nil {
+			return nil, fmt.Errorf("listen on socket %s: %w", config.UnixSocket, err)
+		}
+
+		return listener, nil
+	}
+
+	// Prevent accidental misconfigurations.
+	if config.Port == 0 && !config.Ephemeral {
+		return nil, ErrPortRequired
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidPort, config.Port)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	return listener, nil
+}
 
 // StartServer starts a new GRPC server on the specified port.
 //
-// You must ensure to properly close the server when you are done, using the CloseGRPCServer method.
+// You must ensure to properly close the server when you are done, using the CloseServer method.
 //
-//	listener, server := deploy.StartGRPCServer(50051)
+//	listener, server := arpc.StartServer(50051)
 //	// Graceful shutdown.
-//	defer deploy.CloseGRPCServer(listener, server)
+//	defer arpc.CloseServer(listener, server)
 func StartServer(port int) (net.Listener, *grpc.Server, error) {
-	// Prevent accidental misconfigurations.
-	if port == 0 {
-		return nil, nil, ErrPortRequired
-	}
+	return StartServerWithConfig(&ServerConfig{Port: port})
+}
 
-	// Start to listen on the provided port.
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// StartServerWithConfig is similar to StartServer, but allows more control over the listener and server.
+//
+//	// Let the system pick a free port, on the loopback interface only.
+//	listener, server := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
+//	port := arpc.ListenerPort(listener)
+func StartServerWithConfig(config *ServerConfig) (net.Listener, *grpc.Server, error) {
+	listener, err := config.listen()
 	if err != nil {
-		return nil, nil, fmt.Errorf("listen: %w", err)
+		return nil, nil, err
+	}
+
+	if config.Admin != nil {
+		adminListener, err := startAdmin(config.Admin, listener)
+		if err != nil {
+			_ = listener.Close()
+			return nil, nil, err
+		}
+
+		listener = adminListener
+	}
+
+	options := config.Options
+	if config.TLS != nil {
+		options = append([]grpc.ServerOption{grpc.Creds(credentials.NewTLS(config.TLS))}, options...)
 	}
 
-	server := grpc.NewServer()
+	server := grpc.NewServer(options...)
 
 	return listener, server, nil
 }
@@ -39,3 +131,30 @@ func CloseServer(listener net.Listener, server *grpc.Server) {
 	server.GracefulStop()
 	_ = listener.Close()
 }
+
+// PortFromEnv reads the port to listen on from the PortEnv environment variable, as set by Cloud Run. The default
+// port is returned when the variable is not set.
+func PortFromEnv(defaultPort int) (int, error) {
+	rawPort := os.Getenv(PortEnv)
+	if rawPort == "" {
+		return defaultPort, nil
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("%w: %s=%q", ErrInvalidPort, PortEnv, rawPort)
+	}
+
+	return port, nil
+}
+
+// ListenerPort returns the actual port a listener is bound to. This is useful to retrieve the port picked by the
+// system, when using ServerConfig.Ephemeral. It returns 0 for non TCP listeners.
+func ListenerPort(listener net.Listener) int {
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return 0
+	}
+
+	return addr.Port
+}
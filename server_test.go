@@ -2,10 +2,14 @@ package arpc_test
 
 import (
 	"context"
+	"net"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	testgrpc "google.golang.org/grpc/interop/grpc_testing"
 
 	"github.com/a-novel-kit/arpc"
@@ -23,7 +27,7 @@ func setupServerStubServer(t *testing.T) *arpcmocks.StubServer {
 }
 
 func TestServerServing(t *testing.T) {
-	listener, server, err := arpc.StartServer(8080)
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Ephemeral: true, Host: "127.0.0.1"})
 	require.NoError(t, err)
 	defer arpc.CloseServer(listener, server)
 
@@ -33,10 +37,13 @@ func TestServerServing(t *testing.T) {
 		require.NoError(t, server.Serve(listener))
 	}()
 
+	port := arpc.ListenerPort(listener)
+	require.NotZero(t, port)
+
 	connPool := arpc.NewConnPool(false)
 	defer connPool.Close()
 
-	conn, err := connPool.Open(context.Background(), "127.0.0.1", 8080, arpc.ProtocolHTTPS)
+	conn, err := connPool.Open(context.Background(), "127.0.0.1", port, arpc.ProtocolHTTPS)
 	require.NoError(t, err)
 
 	client := testgrpc.NewTestServiceClient(conn)
@@ -51,3 +58,102 @@ func TestServerNoPort(t *testing.T) {
 	_, _, err := arpc.StartServer(0)
 	require.ErrorIs(t, err, arpc.ErrPortRequired)
 }
+
+func TestServerInvalidPort(t *testing.T) {
+	_, _, err := arpc.StartServer(-1)
+	require.ErrorIs(t, err, arpc.ErrInvalidPort)
+}
+
+func TestServerCustomListener(t *testing.T) {
+	customListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{Listener: customListener})
+	require.NoError(t, err)
+	defer arpc.CloseServer(listener, server)
+
+	require.Equal(t, customListener, listener)
+	require.Equal(t, customListener.Addr().(*net.TCPAddr).Port, arpc.ListenerPort(listener))
+}
+
+func TestServerUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "arpc.sock")
+
+	listener, server, err := arpc.StartServerWithConfig(&arpc.ServerConfig{UnixSocket: socket})
+	require.NoError(t, err)
+	defer arpc.CloseServer(listener, server)
+
+	require.Zero(t, arpc.ListenerPort(listener))
+
+	testgrpc.RegisterTestServiceServer(server, setupServerStubServer(t))
+
+	go func() {
+		require.NoError(t, server.Serve(listener))
+	}()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := testgrpc.NewTestServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.EmptyCall(ctx, new(testgrpc.Empty))
+	require.NoError(t, err)
+}
+
+func TestPortFromEnv(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		env         string
+		defaultPort int
+
+		expect    int
+		expectErr error
+	}{
+		{
+			name: "Default",
+
+			env:         "",
+			defaultPort: 8080,
+
+			expect: 8080,
+		},
+		{
+			name: "FromEnv",
+
+			env:         "9090",
+			defaultPort: 8080,
+
+			expect: 9090,
+		},
+		{
+			name: "NotANumber",
+
+			env:         "foo",
+			defaultPort: 8080,
+
+			expectErr: arpc.ErrInvalidPort,
+		},
+		{
+			name: "OutOfRange",
+
+			env:         "70000",
+			defaultPort: 8080,
+
+			expectErr: arpc.ErrInvalidPort,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Setenv(arpc.PortEnv, testCase.env)
+
+			port, err := arpc.PortFromEnv(testCase.defaultPort)
+			require.ErrorIs(t, err, testCase.expectErr)
+			require.Equal(t, testCase.expect, port)
+		})
+	}
+}
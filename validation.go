@@ -0,0 +1,156 @@
+package arpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Messages generated with protoc-gen-validate expose both methods. ValidateAll is preferred when available, as it
+// reports every violation at once.
+func validateMessage(message interface{}) error {
+	if v, ok := message.(interface{ ValidateAll() error }); ok {
+		return v.ValidateAll()
+	}
+
+	if v, ok := message.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+func fieldViolations(err error, prefix string) []*errdetails.BadRequest_FieldViolation {
+	// Expand multi errors, whether they come from protoc-gen-validate or errors.Join.
+	var children []error
+
+	switch multi := err.(type) { //nolint:errorlint
+	case interface{ AllErrors() []error }:
+		children = multi.AllErrors()
+	case interface{ Unwrap() []error }:
+		children = multi.Unwrap()
+	}
+
+	if children != nil {
+		var output []*errdetails.BadRequest_FieldViolation
+		for _, child := range children {
+			output = append(output, fieldViolations(child, prefix)...)
+		}
+
+		return output
+	}
+
+	// Errors generated by protoc-gen-validate describe the field at fault.
+	var fieldErr interface {
+		error
+		Field() string
+		Reason() string
+	}
+	if !errors.As(err, &fieldErr) {
+		return []*errdetails.BadRequest_FieldViolation{{Field: prefix, Description: err.Error()}}
+	}
+
+	field := fieldErr.Field()
+	if prefix != "" {
+		field = prefix + "." + field
+	}
+
+	// Errors on embedded messages carry the violations of the embedded fields as their cause.
+	if causer, ok := fieldErr.(interface{ Cause() error }); ok && causer.Cause() != nil {
+		if _, nested := causer.Cause().(interface{ Field() string }); nested { //nolint:errorlint
+			return fieldViolations(causer.Cause(), field)
+		}
+	}
+
+	return []*errdetails.BadRequest_FieldViolation{{Field: field, Description: fieldErr.Reason()}}
+}
+
+func validationError(code codes.Code, err error) error {
+	st, detailsErr := status.New(code, err.Error()).WithDetails(&errdetails.BadRequest{
+		FieldViolations: fieldViolations(err, ""),
+	})
+	if detailsErr != nil {
+		return status.Error(code, err.Error()) //nolint:wrapcheck
+	}
+
+	return st.Err() //nolint:wrapcheck
+}
+
+func validateRequest(message interface{}) error {
+	if err := validateMessage(message); err != nil {
+		return validationError(codes.InvalidArgument, err)
+	}
+
+	return nil
+}
+
+// An invalid response is a bug in the server, not a client mistake.
+func validateResponse(message interface{}) error {
+	if err := validateMessage(message); err != nil {
+		return validationError(codes.Internal, err)
+	}
+
+	return nil
+}
+
+// UnaryValidationInterceptor validates incoming messages that expose a Validate or ValidateAll method, such as
+// messages generated by protoc-gen-validate. Invalid requests are rejected with codes.InvalidArgument, and a
+// BadRequest detail listing the field violations.
+//
+// Outside of release mode, responses are validated too, and result in codes.Internal errors when invalid.
+func UnaryValidationInterceptor(release bool) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := validateRequest(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil || release {
+			return resp, err
+		}
+
+		if err = validateResponse(resp); err != nil {
+			return nil, err
+		}
+
+		return resp, nil
+	}
+}
+
+type validatedStream struct {
+	grpc.ServerStream
+
+	release bool
+}
+
+func (stream *validatedStream) RecvMsg(m interface{}) error {
+	if err := stream.ServerStream.RecvMsg(m); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return validateRequest(m)
+}
+
+func (stream *validatedStream) SendMsg(m interface{}) error {
+	if !stream.release {
+		if err := validateResponse(m); err != nil {
+			return err
+		}
+	}
+
+	return stream.ServerStream.SendMsg(m) //nolint:wrapcheck
+}
+
+// StreamValidationInterceptor validates every message received by a stream, the same way
+// UnaryValidationInterceptor does. Outside of release mode, sent messages are validated too.
+func StreamValidationInterceptor(release bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatedStream{ServerStream: ss, release: release})
+	}
+}
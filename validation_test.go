@@ -0,0 +1,200 @@
+package arpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/a-novel-kit/arpc"
+)
+
+// Mimic the errors generated by protoc-gen-validate.
+type fakeFieldError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e fakeFieldError) Error() string  { return "invalid " + e.field + ": " + e.reason }
+func (e fakeFieldError) Field() string  { return e.field }
+func (e fakeFieldError) Reason() string { return e.reason }
+func (e fakeFieldError) Cause() error   { return e.cause }
+
+type fakeMultiError []error
+
+func (e fakeMultiError) Error() string      { return errors.Join(e...).Error() }
+func (e fakeMultiError) AllErrors() []error { return e }
+
+type fakeValidated struct {
+	err error
+}
+
+func (m *fakeValidated) Validate() error { return m.err }
+
+type fakeAllValidated struct {
+	err error
+}
+
+func (m *fakeAllValidated) Validate() error    { return errors.New("should not be called") }
+func (m *fakeAllValidated) ValidateAll() error { return m.err }
+
+func TestUnaryValidationInterceptor(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		release bool
+		req     interface{}
+		resp    interface{}
+
+		expectCode       codes.Code
+		expectViolations []*errdetails.BadRequest_FieldViolation
+	}{
+		{
+			name: "NoValidation",
+
+			req:  "foo",
+			resp: "bar",
+
+			expectCode: codes.OK,
+		},
+		{
+			name: "Valid",
+
+			req:  &fakeValidated{},
+			resp: &fakeValidated{},
+
+			expectCode: codes.OK,
+		},
+		{
+			name: "InvalidRequest",
+
+			req: &fakeValidated{err: fakeFieldError{field: "Name", reason: "value is required"}},
+
+			expectCode: codes.InvalidArgument,
+			expectViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "Name", Description: "value is required"},
+			},
+		},
+		{
+			name: "ValidateAll",
+
+			req: &fakeAllValidated{err: fakeMultiError{
+				fakeFieldError{field: "Name", reason: "value is required"},
+				fakeFieldError{field: "Address", reason: "embedded message failed validation", cause: fakeFieldError{
+					field: "Street", reason: "value length must be at least 1 runes",
+				}},
+			}},
+
+			expectCode: codes.InvalidArgument,
+			expectViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "Name", Description: "value is required"},
+				{Field: "Address.Street", Description: "value length must be at least 1 runes"},
+			},
+		},
+		{
+			name: "GenericError",
+
+			req: &fakeValidated{err: errors.New("uwups")},
+
+			expectCode: codes.InvalidArgument,
+			expectViolations: []*errdetails.BadRequest_FieldViolation{
+				{Description: "uwups"},
+			},
+		},
+		{
+			name: "InvalidResponse",
+
+			req:  &fakeValidated{},
+			resp: &fakeValidated{err: fakeFieldError{field: "ID", reason: "value is required"}},
+
+			expectCode: codes.Internal,
+			expectViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "ID", Description: "value is required"},
+			},
+		},
+		{
+			name: "InvalidResponseRelease",
+
+			release: true,
+			req:     &fakeValidated{},
+			resp:    &fakeValidated{err: fakeFieldError{field: "ID", reason: "value is required"}},
+
+			expectCode: codes.OK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			interceptor := arpc.UnaryValidationInterceptor(testCase.release)
+
+			_, err := interceptor(
+				context.Background(),
+				testCase.req,
+				&grpc.UnaryServerInfo{FullMethod: "/foo.Foo/Bar"},
+				func(_ context.Context, _ interface{}) (interface{}, error) {
+					return testCase.resp, nil
+				},
+			)
+			require.Equal(t, testCase.expectCode, status.Code(err))
+
+			if testCase.expectViolations == nil {
+				return
+			}
+
+			details := status.Convert(err).Details()
+			require.Len(t, details, 1)
+
+			badRequest, ok := details[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.Len(t, badRequest.GetFieldViolations(), len(testCase.expectViolations))
+
+			for i, violation := range badRequest.GetFieldViolations() {
+				require.Equal(t, testCase.expectViolations[i].GetField(), violation.GetField())
+				require.Equal(t, testCase.expectViolations[i].GetDescription(), violation.GetDescription())
+			}
+		})
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	recv *fakeValidated
+	sent []interface{}
+}
+
+func (stream *fakeServerStream) RecvMsg(m interface{}) error {
+	*(m.(*fakeValidated)) = *stream.recv
+	return nil
+}
+
+func (stream *fakeServerStream) SendMsg(m interface{}) error {
+	stream.sent = append(stream.sent, m)
+	return nil
+}
+
+func TestStreamValidationInterceptor(t *testing.T) {
+	stream := &fakeServerStream{recv: &fakeValidated{err: fakeFieldError{field: "Name", reason: "value is required"}}}
+
+	err := arpc.StreamValidationInterceptor(false)(
+		nil,
+		stream,
+		&grpc.StreamServerInfo{FullMethod: "/foo.Foo/Bar"},
+		func(_ interface{}, ss grpc.ServerStream) error {
+			require.Equal(t, codes.InvalidArgument, status.Code(ss.RecvMsg(new(fakeValidated))))
+
+			require.Equal(t, codes.Internal, status.Code(ss.SendMsg(&fakeValidated{err: errors.New("uwups")})))
+			require.NoError(t, ss.SendMsg(&fakeValidated{}))
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, stream.sent, 1)
+}